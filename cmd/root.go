@@ -8,14 +8,32 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/fatih/color"
+	"github.com/saschakiefer/cf-log-pretty/internal/alert"
 	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/contextlines"
+	"github.com/saschakiefer/cf-log-pretty/internal/dedupe"
 	"github.com/saschakiefer/cf-log-pretty/internal/filter"
 	"github.com/saschakiefer/cf-log-pretty/internal/formatter"
+	"github.com/saschakiefer/cf-log-pretty/internal/grouper"
+	"github.com/saschakiefer/cf-log-pretty/internal/input"
+	"github.com/saschakiefer/cf-log-pretty/internal/merge"
 	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+	"github.com/saschakiefer/cf-log-pretty/internal/stats"
+	"github.com/saschakiefer/cf-log-pretty/internal/trace"
+	"github.com/saschakiefer/cf-log-pretty/internal/tui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 var (
@@ -23,9 +41,12 @@ var (
 	cfg     = &config.Config{}
 )
 
+// maxLineLength is the longest log line that is read, JSON logs with large stack traces easily exceed bufio's default
+const maxLineLength = 1024 * 1024
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:     "cf-log-pretty",
+	Use:     "cf-log-pretty [file...]",
 	Version: Version,
 	Short:   "Convert SAP BTP Cloud Foundry logs to human readable format",
 	Long: `cf-log-pretty is a command-line tool designed to format and colorize log output 
@@ -35,9 +56,21 @@ including structured JSON logs, making them easier to read in a terminal.
 It reads from standard input (stdin), allowing you to pipe the output 
 of 'cf logs' directly into it:
 
-    cf logs <app-name> | cf-log-pretty`,
-	PreRunE: validateFlags,
-	Run:     run,
+    cf logs <app-name> | cf-log-pretty
+
+Alternatively, log files (e.g. archived 'cf logs --recent' output) can be given
+as arguments. Gzip and zstd compressed files are decompressed transparently,
+"-" refers to stdin:
+
+    cf-log-pretty app.log archive.log.gz
+
+Options can also be set in $XDG_CONFIG_HOME/cf-log-pretty/config.yaml (or
+config.toml) and in a project-local .cf-log-pretty.yaml (or .toml), using the
+long flag names as keys. Named profiles in these files are selected with
+--profile. Flags given on the command line take precedence.`,
+	Args:    cobra.ArbitraryArgs,
+	PreRunE: preRun,
+	RunE:    run,
 }
 
 func Execute() {
@@ -49,14 +82,100 @@ func Execute() {
 
 func init() {
 	rootCmd.Flags().StringVarP(&cfg.Level, "level", "l", "TRACE", "minimum log level to include (TRACE, DEBUG, INFO, WARN, ERROR)")
+	rootCmd.Flags().StringSliceVar(&cfg.LoggerLevels, "logger-level", []string{}, "minimum log levels for loggers and packages, overriding --level (e.g. 'com.mycompany.*=DEBUG,org.springframework.*=WARN'). The longest matching name wins")
 	rootCmd.Flags().StringVarP(&cfg.RemovePrefix, "remove-logger-prefix", "r", "", "remove given prefix from logger names (e.g. \"com.foo.prod.\")")
 	rootCmd.Flags().BoolVarP(&cfg.LoggerNameOnly, "show-logger-name-only", "n", false, "remove complete package prefix from logger names")
-	rootCmd.Flags().StringSliceVarP(&cfg.Exclude, "exclude-logger", "e", []string{}, "exclude logs from given loggers. Supports exact match (e.g. \"com.foo.Service\") or package wildcard (e.g. \"com.foo.core.*\" for packages and sub-packages)")
-	rootCmd.Flags().BoolVarP(&cfg.TruncateRaw, "truncate-raw", "t", false, "truncate raw log messages to terminal width (if message is not in JSON format, e.g. platform logs)")
+	rootCmd.Flags().StringSliceVarP(&cfg.Exclude, "exclude-logger", "e", []string{}, "exclude logs from given loggers. Supports exact match (e.g. \"com.foo.Service\"), package wildcard (e.g. \"com.foo.core.*\" for packages and sub-packages), globs (e.g. \"com.**.orders.*\") and regular expressions (e.g. \"/Order(Service|Client)$/\")")
+	rootCmd.Flags().StringSliceVar(&cfg.Include, "include-logger", []string{}, "only include logs from given loggers, with the same patterns as --exclude-logger. Excluded loggers stay excluded")
+	rootCmd.Flags().BoolVarP(&cfg.ShowSource, "show-source", "s", false, "show the source of each log, abbreviated (e.g. \"WEB/2\" for \"APP/PROC/WEB/2\", \"RTR\") and colored per instance")
+	rootCmd.Flags().StringSliceVar(&cfg.Sources, "source", []string{}, "only include logs of the given sources: source types (e.g. \"RTR\"), globs (e.g. \"APP/PROC/WEB/*\") or, prefixed with \"!\", sources to exclude (e.g. \"!CELL\")")
+	rootCmd.Flags().BoolVarP(&cfg.TruncateRaw, "truncate-raw", "t", false, "truncate raw log messages (not in JSON format, e.g. platform logs) and router access logs to terminal width")
+	rootCmd.Flags().StringArrayVar(&cfg.Grep, "grep", []string{}, "only include logs whose message, logger or stack trace contains the given text or matches the given /regular expression/, and highlight the matches (can be repeated, one match is enough)")
+	rootCmd.Flags().StringArrayVar(&cfg.GrepV, "grep-v", []string{}, "exclude logs whose message, logger or stack trace contains the given text or matches the given /regular expression/ (can be repeated)")
+	rootCmd.Flags().BoolVarP(&cfg.Count, "count", "c", false, "only print the number of matching logs per level at the end of the input (or on Ctrl+C)")
+	rootCmd.Flags().IntVarP(&cfg.BeforeContext, "before-context", "B", 0, "also show the given number of logs before each matching log")
+	rootCmd.Flags().IntVarP(&cfg.AfterContext, "after-context", "A", 0, "also show the given number of logs after each matching log")
+	rootCmd.Flags().IntVarP(&cfg.Context, "context", "C", 0, "also show the given number of logs before and after each matching log (overridden by -A and -B)")
+	rootCmd.Flags().StringVar(&cfg.ContextBy, "context-by", "", "only show logs of the same instance or request as context: instance or correlation (logs before a match are kept for the last 1000 instances or requests)")
+	rootCmd.Flags().BoolVar(&cfg.Dedupe, "dedupe", false, "collapse consecutive repetitions of a log (ignoring numbers, UUIDs and hex IDs) into one line with a counter")
+	rootCmd.Flags().DurationVar(&cfg.DedupeWindow, "dedupe-window", 5*time.Second, "with --dedupe, the maximum time between two repetitions; a collapsed log is printed once no repetition arrives within this time")
+	rootCmd.Flags().BoolVar(&cfg.Summary, "summary", false, "only print statistics at the end of the input (or on Ctrl+C): counts per level, top loggers and errors, errors per minute, router status codes and response times")
+	rootCmd.Flags().DurationVar(&cfg.SummaryEvery, "summary-every", 0, "with --summary, also print the statistics periodically (e.g. 1m)")
+	rootCmd.Flags().IntVar(&cfg.Top, "top", 10, "with --summary, the number of loggers and errors listed")
+	rootCmd.Flags().StringArrayVar(&cfg.Alerts, "alert", []string{}, "run a command or post to a webhook when logs match, e.g. 'when=level>=ERROR; rate=5/1m; webhook=http://localhost:8080/hook' (keys: name, when, rate, debounce, exec, webhook; can be repeated)")
+	rootCmd.Flags().StringVar(&cfg.Since, "since", "", "only include logs at or after the given time: an absolute time (e.g. \"2024-05-01 14:30\"), a time of day on the day of the first log (e.g. 14:30), a duration before now (e.g. 15m) or a duration after the first log (e.g. +10m)")
+	rootCmd.Flags().StringVar(&cfg.Until, "until", "", "only include logs at or before the given time, in the formats of --since. Reading a chronologically sorted input stops once it passes this time")
+	rootCmd.Flags().StringVarP(&cfg.Where, "where", "w", "", "only include logs matching the given filter expression (e.g. 'level>=WARN && logger~\"com.foo.*\" && msg contains \"timeout\"')")
+	rootCmd.Flags().StringVar(&cfg.Trace, "trace", "", "collect all logs of the given correlation ID across instances and the router and print them as timeline at the end of the input (or on Ctrl+C)")
+	rootCmd.Flags().BoolVar(&cfg.ExpandJSON, "expand-json", false, "pretty-print JSON and key=value payloads embedded in messages below the message, with syntax colors")
+	rootCmd.Flags().IntVar(&cfg.ExpandDepth, "expand-depth", 3, "with --expand-json, collapse arrays nested deeper than this")
+	rootCmd.Flags().BoolVar(&cfg.Highlight, "highlight", false, "color IDs, URLs, HTTP methods and status codes, timestamps, IP addresses, durations, numbers, quoted strings and exception class names within messages")
+	rootCmd.Flags().StringVar(&cfg.Color, "color", "auto", "when to color the output: auto (if stdout is a terminal, honoring NO_COLOR, FORCE_COLOR and TERM=dumb), always or never")
+	rootCmd.Flags().StringVar(&cfg.Theme, "theme", formatter.DefaultTheme, "color theme: dark, light, solarized, high-contrast or a theme of the config files")
+	rootCmd.Flags().StringVarP(&cfg.Output, "output", "o", "pretty", "output format: pretty, jsonl, logfmt or csv")
+	rootCmd.Flags().StringVar(&cfg.Template, "template", "", "Go template for the pretty output lines (e.g. '{{.Time | short}} {{.Level | color}} {{.Logger | width 30}} {{.Message}}')")
+	rootCmd.Flags().StringVarP(&cfg.Profile, "profile", "p", "", "apply the options of the given profile from the config files")
+	rootCmd.Flags().BoolVarP(&cfg.Interactive, "interactive", "i", false, "show logs in a full-screen terminal UI with scrolling, search and runtime filters")
+	rootCmd.Flags().StringVar(&cfg.TimeFormat, "time-format", "cf", "format of timestamps: cf (as reported by cf logs), local, utc, rfc3339, time-only, relative (since the previous line) or a Go time layout (e.g. \"15:04:05.000\")")
+	rootCmd.Flags().StringSliceVar(&cfg.Labels, "label", []string{}, "labels shown in front of the lines of each input file, in the order of the files (default: file names)")
+	rootCmd.Flags().DurationVar(&cfg.ReorderWindow, "reorder-window", 0, "when merging several inputs, print a line after waiting this long for the other inputs, instead of waiting until each input has a newer line (use for live streams)")
+	rootCmd.Flags().DurationVarP(&cfg.GroupTimeout, "group-timeout", "g", 200*time.Millisecond, "time to wait for further stack trace lines of a plain text exception before printing it (0 disables grouping)")
+
+}
+
+func preRun(cmd *cobra.Command, args []string) error {
+	options, err := config.LoadOptions(config.Paths(), cfg.Profile)
+	if err != nil {
+		return err
+	}
+
+	if err := applyOptions(cmd.Flags(), options); err != nil {
+		return err
+	}
+
+	if cfg.Themes, err = config.LoadThemes(config.Paths()); err != nil {
+		return err
+	}
+
+	return validateFlags(cmd, args)
+}
+
+// applyOptions sets the flags named by the options of the config files, unless given on the command line
+func applyOptions(flags *pflag.FlagSet, options map[string]any) error {
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		flag := flags.Lookup(name)
+		if flag == nil || name == "help" || name == "version" || name == "profile" {
+			return fmt.Errorf("unknown option in config file: %s", name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		var err error
+		switch value := options[name].(type) {
+		case []any:
+			values := make([]string, len(value))
+			for i, v := range value {
+				values[i] = fmt.Sprint(v)
+			}
+			if slice, ok := flag.Value.(pflag.SliceValue); ok {
+				err = slice.Replace(values)
+			} else {
+				err = flag.Value.Set(strings.Join(values, ","))
+			}
+		default:
+			err = flag.Value.Set(fmt.Sprint(value))
+		}
 
+		if err != nil {
+			return fmt.Errorf("invalid value for option %s in config file: %w", name, err)
+		}
+	}
+
+	return nil
 }
 
-func validateFlags(_ *cobra.Command, _ []string) error {
+func validateFlags(_ *cobra.Command, args []string) error {
 	// Validate log level
 	level := strings.ToUpper(cfg.Level)
 
@@ -72,6 +191,112 @@ func validateFlags(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("invalid log level: %s (allowed: TRACE, DEBUG, INFO, WARN, ERROR)", cfg.Level)
 	}
 
+	if _, err := filter.ParseLoggerLevels(cfg.LoggerLevels); err != nil {
+		return err
+	}
+
+	if cfg.GroupTimeout < 0 {
+		return fmt.Errorf("invalid group timeout: %s (must not be negative)", cfg.GroupTimeout)
+	}
+
+	if cfg.ReorderWindow < 0 {
+		return fmt.Errorf("invalid reorder window: %s (must not be negative)", cfg.ReorderWindow)
+	}
+
+	if err := formatter.ValidateTimeFormat(cfg.TimeFormat); err != nil {
+		return err
+	}
+
+	if err := filter.ValidateLoggerPatterns(append(slices.Clone(cfg.Include), cfg.Exclude...)); err != nil {
+		return err
+	}
+
+	if err := filter.ValidateSources(cfg.Sources); err != nil {
+		return err
+	}
+
+	if _, err := filter.CompileGreps(append(slices.Clone(cfg.Grep), cfg.GrepV...)); err != nil {
+		return err
+	}
+
+	// Validate input labels
+	if len(cfg.Labels) > max(len(args), 1) {
+		return fmt.Errorf("more labels (%d) than inputs (%d)", len(cfg.Labels), max(len(args), 1))
+	}
+
+	if err := filter.ValidateTimeRange(cfg.Since, cfg.Until, time.Now()); err != nil {
+		return err
+	}
+
+	// Validate filter expression
+	if cfg.Where != "" {
+		if _, err := filter.Compile(cfg.Where); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Interactive && cfg.Trace != "" {
+		return fmt.Errorf("cannot use --interactive and --trace together")
+	}
+
+	// Validate output format
+	if _, err := formatter.New(cfg.Output, cfg); err != nil {
+		return err
+	}
+	if cfg.BeforeContext < 0 || cfg.AfterContext < 0 || cfg.Context < 0 {
+		return fmt.Errorf("invalid number of context logs (must not be negative)")
+	}
+	if cfg.ContextBy != "" && !slices.Contains(contextlines.Keys, cfg.ContextBy) {
+		return fmt.Errorf("invalid context key: %s (allowed: %s)", cfg.ContextBy, strings.Join(contextlines.Keys, ", "))
+	}
+	if (cfg.BeforeContext > 0 || cfg.AfterContext > 0 || cfg.Context > 0) && (cfg.Interactive || cfg.Trace != "" || cfg.Count) {
+		return fmt.Errorf("cannot use context logs with --interactive, --trace or --count")
+	}
+
+	if cfg.Dedupe && cfg.DedupeWindow <= 0 {
+		return fmt.Errorf("invalid dedupe window: %s (must be positive)", cfg.DedupeWindow)
+	}
+	if cfg.Dedupe && (cfg.BeforeContext > 0 || cfg.AfterContext > 0 || cfg.Context > 0 || cfg.Trace != "" || cfg.Count) {
+		return fmt.Errorf("cannot use --dedupe with context logs, --trace or --count")
+	}
+
+	if cfg.Summary && (cfg.Interactive || cfg.Trace != "" || cfg.Count || cfg.Dedupe || cfg.BeforeContext > 0 || cfg.AfterContext > 0 || cfg.Context > 0) {
+		return fmt.Errorf("cannot use --summary with --interactive, --trace, --count, --dedupe or context logs")
+	}
+	if cfg.Summary && cfg.Top <= 0 {
+		return fmt.Errorf("invalid number of top entries: %d (must be positive)", cfg.Top)
+	}
+	if cfg.SummaryEvery < 0 {
+		return fmt.Errorf("invalid summary interval: %s (must not be negative)", cfg.SummaryEvery)
+	}
+
+	if _, err := alert.ParseRules(cfg.Alerts); err != nil {
+		return err
+	}
+	// Alert output and errors go to stderr, which would corrupt the terminal UI
+	if len(cfg.Alerts) > 0 && cfg.Interactive {
+		return fmt.Errorf("cannot use --alert with --interactive")
+	}
+
+	if cfg.Count && (cfg.Interactive || cfg.Trace != "") {
+		return fmt.Errorf("cannot use --count with --interactive or --trace")
+	}
+	if cfg.Output != "" && cfg.Output != "pretty" && (cfg.Interactive || cfg.Trace != "" || cfg.Count || cfg.Summary) {
+		return fmt.Errorf("--output %s cannot be used with --interactive, --trace, --count or --summary", cfg.Output)
+	}
+	if cfg.ExpandJSON && cfg.ExpandDepth <= 0 {
+		return fmt.Errorf("invalid expand depth: %d (must be positive)", cfg.ExpandDepth)
+	}
+	if cfg.Color != "" && cfg.Color != "auto" && cfg.Color != "always" && cfg.Color != "never" {
+		return fmt.Errorf("invalid color mode: %s (allowed: auto, always, never)", cfg.Color)
+	}
+	if _, err := formatter.CompileTheme(cfg.Theme, cfg.Themes, false); err != nil {
+		return err
+	}
+	if err := formatter.ValidateTemplate(cfg.Template); err != nil {
+		return err
+	}
+
 	// Validate logger display option
 	if cfg.LoggerNameOnly && cfg.RemovePrefix != "" {
 		return fmt.Errorf("cannot use --show-logger-name-only and --remove-logger-prefix together")
@@ -80,23 +305,333 @@ func validateFlags(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
-func run(_ *cobra.Command, _ []string) {
+// colorEnabled reports whether the output is colored in the given color mode. In auto mode, a non-empty NO_COLOR
+// disables and a non-empty FORCE_COLOR enables colors, otherwise terminals other than TERM=dumb are colored.
+func colorEnabled(mode string, isTerminal bool) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("FORCE_COLOR") != "" {
+		return true
+	}
+	return isTerminal && os.Getenv("TERM") != "dumb"
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	// Flags are valid at this point, so further errors are not caused by wrong usage
+	cmd.SilenceUsage = true
+
+	color.NoColor = !colorEnabled(cfg.Color, term.IsTerminal(int(os.Stdout.Fd())))
+	colorTerm := os.Getenv("COLORTERM")
+	theme, err := formatter.CompileTheme(cfg.Theme, cfg.Themes, colorTerm == "truecolor" || colorTerm == "24bit")
+	if err != nil {
+		return err
+	}
+	formatter.UseTheme(theme)
+
+	if len(args) == 0 {
+		args = []string{input.Stdin}
+	}
+
+	readers := make([]io.ReadCloser, 0, len(args))
+	for _, name := range args {
+		r, err := input.Open(name)
+		if err != nil {
+			for _, opened := range readers {
+				_ = opened.Close()
+			}
+			return err
+		}
+		readers = append(readers, r)
+	}
+
 	f := filter.New(cfg)
 
-	scanner := bufio.NewScanner(os.Stdin)
+	// Every input is grouped on its own, as instances of different apps share the same source names
+	labels := inputLabels(args)
+	streams := make([]<-chan *parser.LogMessage, len(readers))
+	for i, r := range readers {
+		streams[i] = readMessages(args[i], labels[i], r)
+		if cfg.GroupTimeout > 0 {
+			streams[i] = grouper.New(cfg.GroupTimeout).Run(streams[i])
+		}
+	}
+
+	messages := streams[0]
+	if len(streams) > 1 {
+		messages = merge.New(cfg.ReorderWindow).Run(streams)
+	}
+
+	if f.Until != nil {
+		messages = untilPassed(messages, f)
+	}
+
+	// Alerts see all messages, independent of the filters for the output
+	if len(cfg.Alerts) > 0 {
+		rules, _ := alert.ParseRules(cfg.Alerts)
+		messages = alert.New(rules).Run(messages)
+	}
+
+	// Duplicates are collapsed after filtering, so logs filtered out in between don't interrupt a repetition
+	if cfg.Dedupe {
+		messages = dedupe.New(cfg.DedupeWindow).Run(matching(messages, f))
+	}
+
+	if cfg.Trace != "" {
+		runTrace(messages, f)
+		return nil
+	}
+
+	if cfg.Interactive {
+		return tui.Run(messages, f, cfg)
+	}
+
+	if cfg.Count {
+		runCount(messages, f)
+		return nil
+	}
+
+	if cfg.Summary {
+		runSummary(messages, f)
+		return nil
+	}
+
+	out, err := formatter.New(cfg.Output, cfg)
+	if err != nil {
+		return err
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	before, after := cfg.BeforeContext, cfg.AfterContext
+	if before == 0 {
+		before = cfg.Context
+	}
+	if after == 0 {
+		after = cfg.Context
+	}
 
-		msg, ok := parser.ParseLine(line)
-		if !ok {
-			continue // skip malformed lines
+	if before > 0 || after > 0 {
+		for line := range contextlines.New(before, after, cfg.ContextBy, f.Matches).Run(messages) {
+			switch {
+			case !line.Separator:
+				fmt.Println(out.Format(line.Msg))
+			case cfg.Output == "" || cfg.Output == "pretty":
+				fmt.Println(contextSeparator("--"))
+			}
 		}
+		return nil
+	}
 
+	for msg := range messages {
 		if !f.Matches(msg) {
 			continue
 		}
 
-		fmt.Println(formatter.Format(msg, formatter.LevelColorizer(msg.Level), cfg))
+		fmt.Println(out.Format(msg))
+	}
+
+	return nil
+}
+
+// matching emits the messages passing f
+func matching(messages <-chan *parser.LogMessage, f *filter.Filter) <-chan *parser.LogMessage {
+	out := make(chan *parser.LogMessage)
+
+	go func() {
+		defer close(out)
+		for msg := range messages {
+			if f.Matches(msg) {
+				out <- msg
+			}
+		}
+	}()
+
+	return out
+}
+
+// untilPassed emits the messages until the input passes the end of the time range of f. Only inputs that are
+// sorted chronologically so far are ended early, otherwise a later log may still be within the range.
+func untilPassed(messages <-chan *parser.LogMessage, f *filter.Filter) <-chan *parser.LogMessage {
+	out := make(chan *parser.LogMessage)
+
+	go func() {
+		defer close(out)
+
+		sorted, last := true, time.Time{}
+		for msg := range messages {
+			if !msg.Time.IsZero() {
+				sorted = sorted && !msg.Time.Before(last)
+				last = msg.Time
+			}
+			if sorted && f.PastUntil(msg) {
+				return
+			}
+			out <- msg
+		}
+	}()
+
+	return out
+}
+
+// contextSeparator is printed between groups of logs shown with context that don't follow each other
+var contextSeparator = formatter.NewColor(color.Faint).SprintFunc()
+
+// runTrace collects the messages of the traced correlation ID and prints them as timeline
+// once the input ends or the user interrupts a live stream
+func runTrace(messages <-chan *parser.LogMessage, f *filter.Filter) {
+	timeline := trace.New(cfg.Trace)
+	collect(messages, f, func(msg *parser.LogMessage) { timeline.Add(msg) })
+	fmt.Println(timeline.Render(cfg))
+}
+
+// runCount counts the matching messages per level and prints the counts
+// once the input ends or the user interrupts a live stream
+func runCount(messages <-chan *parser.LogMessage, f *filter.Filter) {
+	counts := map[string]int{}
+	collect(messages, f, func(msg *parser.LogMessage) {
+		counts[countLevel(msg.Level)]++
+	})
+	fmt.Print(renderCounts(counts))
+}
+
+// runSummary collects statistics of the matching messages and prints them once the input ends or the user
+// interrupts a live stream, and with --summary-every periodically
+func runSummary(messages <-chan *parser.LogMessage, f *filter.Filter) {
+	s := stats.New(cfg.Top)
+
+	done := make(chan struct{})
+	if cfg.SummaryEvery > 0 {
+		ticker := time.NewTicker(cfg.SummaryEvery)
+		defer ticker.Stop()
+
+		go func() {
+			for {
+				select {
+				case <-ticker.C:
+					fmt.Println(s.Render())
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	collect(messages, f, s.Add)
+	close(done)
+	fmt.Print(s.Render())
+}
+
+// collect passes the messages matching f to add until the input ends or the user presses Ctrl+C
+func collect(messages <-chan *parser.LogMessage, f *filter.Filter, add func(*parser.LogMessage)) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if f.Matches(msg) {
+				add(msg)
+			}
+		case <-interrupt:
+			return
+		}
+	}
+}
+
+// countLevels are always listed by --count, other levels only if they occur
+var countLevels = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "-----"}
+
+// countLevel returns the level a message is counted for
+func countLevel(level string) string {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	if level == "" {
+		return "-----"
+	}
+	return level
+}
+
+// renderCounts lists the counts per level, followed by the total
+func renderCounts(counts map[string]int) string {
+	levels := slices.Clone(countLevels)
+	for _, level := range slices.Sorted(maps.Keys(counts)) {
+		if !slices.Contains(levels, level) {
+			levels = append(levels, level)
+		}
+	}
+
+	var sb strings.Builder
+	total := 0
+	for _, level := range levels {
+		sb.WriteString(fmt.Sprintf("%s %8d\n", formatter.LevelColorizer(level)("%-5s", level), counts[level]))
+		total += counts[level]
+	}
+	sb.WriteString(fmt.Sprintf("%-5s %8d\n", "TOTAL", total))
+	return sb.String()
+}
+
+// readMessages parses the lines of r in the background and emits them on the returned channel.
+// A read error is reported on stderr and ends the input.
+func readMessages(name string, label string, r io.ReadCloser) <-chan *parser.LogMessage {
+	out := make(chan *parser.LogMessage)
+
+	go func() {
+		defer close(out)
+		defer func() { _ = r.Close() }()
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+
+		for scanner.Scan() {
+			msg, ok := parser.ParseLine(scanner.Text())
+			if !ok {
+				continue // skip malformed lines
+			}
+
+			msg.Label = label
+			out <- msg
+		}
+
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "error reading %s: %v\n", name, err)
+		}
+	}()
+
+	return out
+}
+
+// inputLabels returns the labels shown in front of each line, padded to the same width. A single input is not labeled.
+// Labels given by flag take precedence over the file names.
+func inputLabels(names []string) []string {
+	labels := make([]string, len(names))
+	if len(names) < 2 {
+		return labels
+	}
+
+	width := 0
+	for i, name := range names {
+		switch {
+		case i < len(cfg.Labels):
+			labels[i] = cfg.Labels[i]
+		case name == input.Stdin:
+			labels[i] = "stdin"
+		default:
+			labels[i] = filepath.Base(name)
+		}
+		width = max(width, len(labels[i]))
+	}
+
+	for i := range labels {
+		labels[i] = fmt.Sprintf("%-*s", width, labels[i])
 	}
+	return labels
 }
@@ -7,8 +7,13 @@ package cmd
 
 import (
 	"testing"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/filter"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+	"github.com/spf13/pflag"
 )
 
 func TestValidateFlags(t *testing.T) {
@@ -134,6 +139,453 @@ func TestValidateFlags(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "valid with group timeout",
+			config: &config.Config{
+				Level:        "INFO",
+				GroupTimeout: 500 * time.Millisecond,
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid: negative group timeout",
+			config: &config.Config{
+				Level:        "INFO",
+				GroupTimeout: -time.Second,
+			},
+			expectError: true,
+			errorMsg:    "invalid group timeout: -1s (must not be negative)",
+		},
+		{
+			name: "valid with filter expression",
+			config: &config.Config{
+				Level: "INFO",
+				Where: `level>=WARN && msg contains "timeout"`,
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid filter expression",
+			config: &config.Config{
+				Level: "INFO",
+				Where: "level>=",
+			},
+			expectError: true,
+			errorMsg:    "invalid filter expression: unexpected end of expression",
+		},
+		{
+			name: "valid with named time format",
+			config: &config.Config{
+				Level:      "INFO",
+				TimeFormat: "relative",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid time format",
+			config: &config.Config{
+				Level:      "INFO",
+				TimeFormat: "foo",
+			},
+			expectError: true,
+			errorMsg:    "invalid time format: foo (allowed: cf, local, utc, rfc3339, time-only, relative or a Go time layout like \"15:04:05.000\")",
+		},
+		{
+			name: "invalid: both interactive and trace",
+			config: &config.Config{
+				Level:       "INFO",
+				Interactive: true,
+				Trace:       "abc",
+			},
+			expectError: true,
+			errorMsg:    "cannot use --interactive and --trace together",
+		},
+		{
+			name: "valid with output format",
+			config: &config.Config{
+				Level:  "INFO",
+				Output: "jsonl",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid output format",
+			config: &config.Config{
+				Level:  "INFO",
+				Output: "xml",
+			},
+			expectError: true,
+			errorMsg:    "invalid output format: xml (allowed: pretty, jsonl, logfmt, csv)",
+		},
+		{
+			name: "invalid: structured output in interactive mode",
+			config: &config.Config{
+				Level:       "INFO",
+				Output:      "csv",
+				Interactive: true,
+			},
+			expectError: true,
+			errorMsg:    "--output csv cannot be used with --interactive, --trace, --count or --summary",
+		},
+		{
+			name: "invalid: structured output with summary",
+			config: &config.Config{
+				Level:   "INFO",
+				Output:  "csv",
+				Summary: true,
+				Top:     10,
+			},
+			expectError: true,
+			errorMsg:    "--output csv cannot be used with --interactive, --trace, --count or --summary",
+		},
+		{
+			name: "invalid: structured output with count",
+			config: &config.Config{
+				Level:  "INFO",
+				Output: "jsonl",
+				Count:  true,
+			},
+			expectError: true,
+			errorMsg:    "--output jsonl cannot be used with --interactive, --trace, --count or --summary",
+		},
+		{
+			name: "valid with logger levels",
+			config: &config.Config{
+				Level:        "INFO",
+				LoggerLevels: []string{"com.mycompany.*=DEBUG", "org.springframework.*=WARN"},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid logger level",
+			config: &config.Config{
+				Level:        "INFO",
+				LoggerLevels: []string{"com.mycompany.*=LOUD"},
+			},
+			expectError: true,
+			errorMsg:    "invalid log level in \"com.mycompany.*=LOUD\" (allowed: TRACE, DEBUG, INFO, WARN, ERROR, OFF)",
+		},
+		{
+			name: "valid with include and exclude loggers",
+			config: &config.Config{
+				Level:   "INFO",
+				Include: []string{"com.foo.*", "/Service$/"},
+				Exclude: []string{"com.foo.noisy.*"},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid logger pattern",
+			config: &config.Config{
+				Level:   "INFO",
+				Include: []string{"/(/"},
+			},
+			expectError: true,
+			errorMsg:    "invalid logger pattern: \"/(/\": error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name: "valid with grep",
+			config: &config.Config{
+				Level: "INFO",
+				Grep:  []string{"timeout", "/time(out|d out)/"},
+				GrepV: []string{"health check"},
+				Count: true,
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid grep pattern",
+			config: &config.Config{
+				Level: "INFO",
+				GrepV: []string{"/a*/"},
+			},
+			expectError: true,
+			errorMsg:    "invalid grep pattern: \"/a*/\" (matches empty text)",
+		},
+		{
+			name: "invalid: count in interactive mode",
+			config: &config.Config{
+				Level:       "INFO",
+				Count:       true,
+				Interactive: true,
+			},
+			expectError: true,
+			errorMsg:    "cannot use --count with --interactive or --trace",
+		},
+		{
+			name: "valid with context",
+			config: &config.Config{
+				Level:         "ERROR",
+				BeforeContext: 5,
+				Context:       2,
+				ContextBy:     "instance",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid: negative context",
+			config: &config.Config{
+				Level:        "ERROR",
+				AfterContext: -1,
+			},
+			expectError: true,
+			errorMsg:    "invalid number of context logs (must not be negative)",
+		},
+		{
+			name: "invalid context key",
+			config: &config.Config{
+				Level:     "ERROR",
+				ContextBy: "thread",
+			},
+			expectError: true,
+			errorMsg:    "invalid context key: thread (allowed: instance, correlation)",
+		},
+		{
+			name: "invalid: context with count",
+			config: &config.Config{
+				Level:   "ERROR",
+				Context: 3,
+				Count:   true,
+			},
+			expectError: true,
+			errorMsg:    "cannot use context logs with --interactive, --trace or --count",
+		},
+		{
+			name: "valid with dedupe",
+			config: &config.Config{
+				Level:        "INFO",
+				Dedupe:       true,
+				DedupeWindow: time.Second,
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid dedupe window",
+			config: &config.Config{
+				Level:        "INFO",
+				Dedupe:       true,
+				DedupeWindow: 0,
+			},
+			expectError: true,
+			errorMsg:    "invalid dedupe window: 0s (must be positive)",
+		},
+		{
+			name: "invalid: dedupe with count",
+			config: &config.Config{
+				Level:        "INFO",
+				Dedupe:       true,
+				DedupeWindow: time.Second,
+				Count:        true,
+			},
+			expectError: true,
+			errorMsg:    "cannot use --dedupe with context logs, --trace or --count",
+		},
+		{
+			name: "valid with summary",
+			config: &config.Config{
+				Level:        "INFO",
+				Summary:      true,
+				SummaryEvery: time.Minute,
+				Top:          5,
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid: summary with count",
+			config: &config.Config{
+				Level:   "INFO",
+				Summary: true,
+				Top:     5,
+				Count:   true,
+			},
+			expectError: true,
+			errorMsg:    "cannot use --summary with --interactive, --trace, --count, --dedupe or context logs",
+		},
+		{
+			name: "invalid number of top entries",
+			config: &config.Config{
+				Level:   "INFO",
+				Summary: true,
+				Top:     0,
+			},
+			expectError: true,
+			errorMsg:    "invalid number of top entries: 0 (must be positive)",
+		},
+		{
+			name: "valid with alert",
+			config: &config.Config{
+				Level:  "INFO",
+				Alerts: []string{"name=errors; when=level>=ERROR; rate=5/1m; exec=notify-send \"$ALERT_MESSAGE\""},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid time range",
+			config: &config.Config{
+				Level: "INFO",
+				Since: "2024-05-01 14:30",
+				Until: "+10m",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid since",
+			config: &config.Config{
+				Level: "INFO",
+				Since: "yesterday",
+			},
+			expectError: true,
+			errorMsg:    "invalid time: yesterday (expected e.g. 2024-05-01T14:30:00Z, \"2024-05-01 14:30\", 14:30, 15m or +10m)",
+		},
+		{
+			name: "invalid time range",
+			config: &config.Config{
+				Level: "INFO",
+				Since: "14:30",
+				Until: "14:00",
+			},
+			expectError: true,
+			errorMsg:    "invalid time range: --since 14:30 is after --until 14:00",
+		},
+		{
+			name: "valid with expanded JSON",
+			config: &config.Config{
+				Level:       "INFO",
+				ExpandJSON:  true,
+				ExpandDepth: 2,
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid expand depth",
+			config: &config.Config{
+				Level:      "INFO",
+				ExpandJSON: true,
+			},
+			expectError: true,
+			errorMsg:    "invalid expand depth: 0 (must be positive)",
+		},
+		{
+			name: "valid with color mode and theme",
+			config: &config.Config{
+				Level: "INFO",
+				Color: "always",
+				Theme: "mine",
+				Themes: map[string]config.Theme{
+					"mine": {Base: "light", Levels: map[string]string{"ERROR": "#ff5f5f bold"}},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid color mode",
+			config: &config.Config{
+				Level: "INFO",
+				Color: "sometimes",
+			},
+			expectError: true,
+			errorMsg:    "invalid color mode: sometimes (allowed: auto, always, never)",
+		},
+		{
+			name: "unknown theme",
+			config: &config.Config{
+				Level: "INFO",
+				Theme: "neon",
+			},
+			expectError: true,
+			errorMsg:    "unknown theme: neon (available: dark, high-contrast, light, solarized)",
+		},
+		{
+			name: "invalid theme color",
+			config: &config.Config{
+				Level: "INFO",
+				Theme: "mine",
+				Themes: map[string]config.Theme{
+					"mine": {Levels: map[string]string{"WARN": "orange"}},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid theme mine: WARN: invalid color \"orange\" (allowed: black, red, green, yellow, blue, magenta, cyan, white, hi-<color>, gray, bold, faint, italic, underline, reverse, 0-255, #rrggbb, bg:<color>)",
+		},
+		{
+			name: "invalid alert without action",
+			config: &config.Config{
+				Level:  "INFO",
+				Alerts: []string{"when=level>=ERROR"},
+			},
+			expectError: true,
+			errorMsg:    "invalid alert \"when=level>=ERROR\": missing action (exec=... or webhook=...)",
+		},
+		{
+			name: "invalid: alert with interactive",
+			config: &config.Config{
+				Level:       "INFO",
+				Interactive: true,
+				Alerts:      []string{"when=level>=ERROR; exec=true"},
+			},
+			expectError: true,
+			errorMsg:    "cannot use --alert with --interactive",
+		},
+		{
+			name: "valid with sources",
+			config: &config.Config{
+				Level:   "INFO",
+				Sources: []string{"APP/PROC/WEB/*", "!CELL"},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid source pattern",
+			config: &config.Config{
+				Level:   "INFO",
+				Sources: []string{"!"},
+			},
+			expectError: true,
+			errorMsg:    "invalid source pattern: \"!\"",
+		},
+		{
+			name: "valid with template",
+			config: &config.Config{
+				Level:    "INFO",
+				Template: "{{.Time | short}} {{.Level | color}} {{.Logger | width 30}} {{.Message}}",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid template",
+			config: &config.Config{
+				Level:    "INFO",
+				Template: "{{.Level | foo}}",
+			},
+			expectError: true,
+			errorMsg:    "invalid template: template: template:1: function \"foo\" not defined",
+		},
+		{
+			name: "valid with label for stdin",
+			config: &config.Config{
+				Level:  "INFO",
+				Labels: []string{"app-a"},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid: more labels than inputs",
+			config: &config.Config{
+				Level:  "INFO",
+				Labels: []string{"app-a", "app-b"},
+			},
+			expectError: true,
+			errorMsg:    "more labels (2) than inputs (1)",
+		},
+		{
+			name: "invalid: negative reorder window",
+			config: &config.Config{
+				Level:         "INFO",
+				ReorderWindow: -time.Second,
+			},
+			expectError: true,
+			errorMsg:    "invalid reorder window: -1s (must not be negative)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -184,3 +636,172 @@ func TestValidateFlagsLogLevelCaseInsensitive(t *testing.T) {
 		})
 	}
 }
+
+func TestInputLabels(t *testing.T) {
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+
+	cfg = &config.Config{Labels: []string{"orders"}}
+
+	labels := inputLabels([]string{"/tmp/logs/orders.log", "/tmp/logs/billing.log.gz", "-"})
+
+	expected := []string{"orders        ", "billing.log.gz", "stdin         "}
+	for i := range expected {
+		if labels[i] != expected[i] {
+			t.Errorf("Expected label %q, got %q", expected[i], labels[i])
+		}
+	}
+
+	if labels := inputLabels([]string{"-"}); labels[0] != "" {
+		t.Errorf("Expected no label for a single input, got %q", labels[0])
+	}
+}
+
+func TestApplyOptions(t *testing.T) {
+	c := &config.Config{}
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVarP(&c.Level, "level", "l", "TRACE", "")
+	flags.StringSliceVarP(&c.Exclude, "exclude-logger", "e", []string{}, "")
+	flags.BoolVarP(&c.TruncateRaw, "truncate-raw", "t", false, "")
+	flags.DurationVarP(&c.GroupTimeout, "group-timeout", "g", 0, "")
+	flags.StringVarP(&c.RemovePrefix, "remove-logger-prefix", "r", "", "")
+
+	if err := flags.Parse([]string{"--level", "ERROR"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	err := applyOptions(flags, map[string]any{
+		"level":                "INFO",
+		"exclude-logger":       []any{"org.springframework.*", "com.foo.Service"},
+		"truncate-raw":         true,
+		"group-timeout":        "1s",
+		"remove-logger-prefix": "com.foo.",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if c.Level != "ERROR" {
+		t.Errorf("Expected command line flag to take precedence, got level %s", c.Level)
+	}
+	if len(c.Exclude) != 2 || c.Exclude[0] != "org.springframework.*" || c.Exclude[1] != "com.foo.Service" {
+		t.Errorf("Unexpected exclude loggers: %v", c.Exclude)
+	}
+	if !c.TruncateRaw || c.GroupTimeout != time.Second || c.RemovePrefix != "com.foo." {
+		t.Errorf("Unexpected config: %+v", c)
+	}
+}
+
+func TestApplyOptions_Errors(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Bool("truncate-raw", false, "")
+	flags.Bool("help", false, "")
+
+	tests := []struct {
+		name     string
+		options  map[string]any
+		errorMsg string
+	}{
+		{"Unknown option", map[string]any{"colour": "red"}, "unknown option in config file: colour"},
+		{"Help is not an option", map[string]any{"help": true}, "unknown option in config file: help"},
+		{"Invalid value", map[string]any{"truncate-raw": "maybe"}, `invalid value for option truncate-raw in config file: strconv.ParseBool: parsing "maybe": invalid syntax`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyOptions(flags, tt.options)
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message %q but got %q", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestRenderCounts(t *testing.T) {
+	color.NoColor = true
+
+	counts := map[string]int{"INFO": 3, "ERROR": 1, "FATAL": 2, countLevel(""): 4}
+	expected := "TRACE        0\n" +
+		"DEBUG        0\n" +
+		"INFO         3\n" +
+		"WARN         0\n" +
+		"ERROR        1\n" +
+		"-----        4\n" +
+		"FATAL        2\n" +
+		"TOTAL       10\n"
+
+	if got := renderCounts(counts); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestUntilPassed(t *testing.T) {
+	first := time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		minutes  []int
+		expected int
+	}{
+		{"Sorted input ends after until", []int{0, 1, 2, 3, 1}, 3},
+		{"Unsorted input is read completely", []int{0, 2, 1, 3, 1}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filter.New(&config.Config{Level: "TRACE", Until: "+2m"})
+
+			in := make(chan *parser.LogMessage, len(tt.minutes))
+			for _, minutes := range tt.minutes {
+				in <- &parser.LogMessage{Time: first.Add(time.Duration(minutes) * time.Minute)}
+			}
+			close(in)
+
+			got := 0
+			for range untilPassed(in, f) {
+				got++
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %d messages, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		env        map[string]string
+		isTerminal bool
+		expected   bool
+	}{
+		{"Auto on terminal", "auto", nil, true, true},
+		{"Auto on pipe", "auto", nil, false, false},
+		{"Auto with NO_COLOR", "auto", map[string]string{"NO_COLOR": "1"}, true, false},
+		{"Auto with empty NO_COLOR", "auto", map[string]string{"NO_COLOR": ""}, true, true},
+		{"Auto with FORCE_COLOR", "auto", map[string]string{"FORCE_COLOR": "1"}, false, true},
+		{"NO_COLOR wins over FORCE_COLOR", "auto", map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, true, false},
+		{"Auto on dumb terminal", "auto", map[string]string{"TERM": "dumb"}, true, false},
+		{"Always", "always", map[string]string{"NO_COLOR": "1"}, false, true},
+		{"Never", "never", map[string]string{"FORCE_COLOR": "1"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("FORCE_COLOR", "")
+			t.Setenv("TERM", "xterm-256color")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			if got := colorEnabled(tt.mode, tt.isTerminal); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/filter"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// DefaultDebounce is the minimum time between two notifications of the same rule
+const DefaultDebounce = time.Minute
+
+// actionTimeout limits how long a command or webhook may take
+const actionTimeout = 10 * time.Second
+
+// Rule notifies about log messages matching a filter expression, e.g.
+//
+//	name=errors; when=level>=ERROR && logger~"com.foo.*"; rate=5/1m; webhook=http://localhost:8080/hook
+type Rule struct {
+	Name     string
+	When     filter.Expr
+	Count    int           // number of matching messages within Window that trigger the rule
+	Window   time.Duration // 0 to trigger on every matching message
+	Debounce time.Duration
+	Exec     string // command run with sh -c
+	Webhook  string // URL the payload is posted to
+
+	matches  []time.Time // times of the matching messages within Window
+	lastFire time.Time
+}
+
+// Payload describes a triggered rule. It is posted to webhooks and passed to commands on stdin.
+type Payload struct {
+	Alert   string    `json:"alert"`
+	Count   int       `json:"count"`
+	Window  string    `json:"window,omitempty"`
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source,omitempty"`
+	Level   string    `json:"level,omitempty"`
+	Logger  string    `json:"logger,omitempty"`
+	Message string    `json:"message"`
+}
+
+// ParseRule parses a rule of semicolon separated key=value pairs: name (optional), when (a filter expression),
+// rate (optional, e.g. "5/1m" for 5 matches within a minute), debounce (optional, default 1m) and at least one
+// of webhook (an HTTP URL) and exec (a shell command, which must be the last key).
+func ParseRule(spec string, index int) (*Rule, error) {
+	rule := &Rule{Name: fmt.Sprintf("alert %d", index+1), Count: 1, Debounce: DefaultDebounce}
+
+	parts := splitOutsideQuotes(spec, ';')
+	for i := 0; i < len(parts); i++ {
+		part := strings.TrimSpace(parts[i])
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok {
+			return nil, fmt.Errorf("invalid alert %q: expected key=value, got %q", spec, part)
+		}
+
+		var err error
+		switch key {
+		case "name":
+			rule.Name = value
+		case "when":
+			rule.When, err = filter.Compile(value)
+		case "rate":
+			rule.Count, rule.Window, err = parseRate(value)
+		case "debounce":
+			rule.Debounce, err = time.ParseDuration(value)
+			if err == nil && rule.Debounce < 0 {
+				err = fmt.Errorf("debounce must not be negative")
+			}
+		case "exec":
+			// The command may contain semicolons itself and extends to the end of the rule
+			rule.Exec = strings.TrimSpace(strings.Join(append([]string{value}, parts[i+1:]...), ";"))
+			i = len(parts)
+		case "webhook":
+			if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+				err = fmt.Errorf("webhook must be an http or https URL")
+			}
+			rule.Webhook = value
+		default:
+			err = fmt.Errorf("unknown key %s (allowed: name, when, rate, debounce, exec, webhook)", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert %q: %w", spec, err)
+		}
+	}
+
+	if rule.When == nil {
+		return nil, fmt.Errorf("invalid alert %q: missing condition (when=...)", spec)
+	}
+	if rule.Exec == "" && rule.Webhook == "" {
+		return nil, fmt.Errorf("invalid alert %q: missing action (exec=... or webhook=...)", spec)
+	}
+	return rule, nil
+}
+
+// ParseRules parses the given rules
+func ParseRules(specs []string) ([]*Rule, error) {
+	rules := make([]*Rule, 0, len(specs))
+	for i, spec := range specs {
+		rule, err := ParseRule(spec, i)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseRate parses a rate like "5/1m"
+func parseRate(value string) (int, time.Duration, error) {
+	count, window, ok := strings.Cut(value, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if !ok || err != nil || n < 1 {
+		return 0, 0, fmt.Errorf("invalid rate %s (expected <count>/<duration>, e.g. 5/1m)", value)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d <= 0 {
+		return 0, 0, fmt.Errorf("invalid rate %s (expected <count>/<duration>, e.g. 5/1m)", value)
+	}
+	return n, d, nil
+}
+
+// splitOutsideQuotes splits s at sep, except within double quotes
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	var current strings.Builder
+	quoted, escaped := false, false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == sep && !quoted:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(parts, current.String())
+}
+
+// Alerter checks the messages of the pipeline against rules and runs their actions
+type Alerter struct {
+	Rules  []*Rule
+	Errors io.Writer // failed actions are reported here
+
+	client  *http.Client
+	running sync.WaitGroup
+}
+
+func New(rules []*Rule) *Alerter {
+	return &Alerter{
+		Rules:  rules,
+		Errors: os.Stderr,
+		client: &http.Client{Timeout: actionTimeout},
+	}
+}
+
+// Run passes all messages from in to the returned channel unchanged, checking them against the rules on the way.
+// The returned channel is closed once in is closed and all running actions have finished.
+func (a *Alerter) Run(in <-chan *parser.LogMessage) <-chan *parser.LogMessage {
+	out := make(chan *parser.LogMessage)
+
+	go func() {
+		defer close(out)
+		defer a.running.Wait()
+
+		for msg := range in {
+			a.Check(msg, time.Now())
+			out <- msg
+		}
+	}()
+
+	return out
+}
+
+// Check evaluates msg against all rules and runs the actions of the triggered ones in the background.
+// The time of msg is used for rates, or now if it is unknown.
+func (a *Alerter) Check(msg *parser.LogMessage, now time.Time) {
+	at := msg.Time
+	if at.IsZero() {
+		at = now
+	}
+
+	for _, rule := range a.Rules {
+		if payload, ok := rule.check(msg, at); ok {
+			a.running.Add(1)
+			go func() {
+				defer a.running.Done()
+				if err := a.notify(rule, payload); err != nil {
+					_, _ = fmt.Fprintf(a.Errors, "alert %s: %v\n", rule.Name, err)
+				}
+			}()
+		}
+	}
+}
+
+// Wait blocks until all running actions have finished
+func (a *Alerter) Wait() {
+	a.running.Wait()
+}
+
+// check records msg if it matches and reports whether the rule triggers
+func (r *Rule) check(msg *parser.LogMessage, at time.Time) (Payload, bool) {
+	if !r.When(msg) {
+		return Payload{}, false
+	}
+
+	r.matches = append(r.matches, at)
+	if r.Window > 0 {
+		start := 0
+		for start < len(r.matches) && at.Sub(r.matches[start]) >= r.Window {
+			start++
+		}
+		r.matches = r.matches[start:]
+	}
+	if len(r.matches) < r.Count {
+		return Payload{}, false
+	}
+
+	count := len(r.matches)
+	r.matches = nil
+	if !r.lastFire.IsZero() && at.Sub(r.lastFire) < r.Debounce {
+		return Payload{}, false
+	}
+	r.lastFire = at
+
+	payload := Payload{
+		Alert:   r.Name,
+		Count:   count,
+		Time:    at,
+		Source:  msg.Source,
+		Level:   msg.Level,
+		Logger:  msg.Logger,
+		Message: msg.Message,
+	}
+	if r.Window > 0 {
+		payload.Window = r.Window.String()
+	}
+	return payload, true
+}
+
+// notify runs the actions of a triggered rule
+func (a *Alerter) notify(rule *Rule, payload Payload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+
+	var errs []string
+	if rule.Exec != "" {
+		cmd := exec.CommandContext(ctx, "sh", "-c", rule.Exec)
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Stdout = a.Errors
+		cmd.Stderr = a.Errors
+		cmd.Env = append(os.Environ(),
+			"ALERT_NAME="+payload.Alert,
+			"ALERT_COUNT="+strconv.Itoa(payload.Count),
+			"ALERT_LEVEL="+payload.Level,
+			"ALERT_LOGGER="+payload.Logger,
+			"ALERT_MESSAGE="+payload.Message,
+		)
+		if err := cmd.Run(); err != nil {
+			errs = append(errs, fmt.Sprintf("command failed: %v", err))
+		}
+	}
+
+	if rule.Webhook != "" {
+		if err := a.post(ctx, rule.Webhook, data); err != nil {
+			errs = append(errs, fmt.Sprintf("webhook failed: %v", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func (a *Alerter) post(ctx context.Context, url string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// helper to build a LogMessage logged the given number of seconds after a fixed time
+func msg(level string, message string, second int) *parser.LogMessage {
+	return &parser.LogMessage{
+		Time:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(second) * time.Second),
+		Source:  "APP/PROC/WEB/0",
+		Level:   level,
+		Logger:  "com.foo.Service",
+		Message: message,
+	}
+}
+
+// webhook is a local stand-in for an HTTP endpoint, recording the received payloads
+type webhook struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []Payload
+}
+
+func newWebhook(t *testing.T, status int) *webhook {
+	w := &webhook{}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected JSON POST, got %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		var p Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("Expected JSON payload, got error: %v", err)
+		}
+		w.mu.Lock()
+		w.payloads = append(w.payloads, p)
+		w.mu.Unlock()
+		rw.WriteHeader(status)
+	}))
+	t.Cleanup(w.Close)
+	return w
+}
+
+func TestAlerter_Webhook(t *testing.T) {
+	hook := newWebhook(t, http.StatusOK)
+
+	rule, err := ParseRule("name=errors; when=level>=ERROR; rate=3/1m; debounce=5m; webhook="+hook.URL, 0)
+	if err != nil {
+		t.Fatalf("Expected rule to be valid, got: %v", err)
+	}
+	a := New([]*Rule{rule})
+
+	messages := []*parser.LogMessage{
+		msg("ERROR", "e1", 0),
+		msg("INFO", "i1", 1),
+		msg("ERROR", "e2", 30),
+		msg("ERROR", "e3", 70), // e1 is outside the window
+		msg("ERROR", "e4", 80), // triggers with e2, e3, e4
+		msg("ERROR", "e5", 90),
+		msg("ERROR", "e6", 91),
+		msg("ERROR", "e7", 92), // debounced
+	}
+
+	in := make(chan *parser.LogMessage)
+	go func() {
+		defer close(in)
+		for _, m := range messages {
+			in <- m
+		}
+	}()
+
+	count := 0
+	for range a.Run(in) {
+		count++
+	}
+	if count != len(messages) {
+		t.Errorf("Expected all %d messages to be passed on, got %d", len(messages), count)
+	}
+
+	if len(hook.payloads) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(hook.payloads))
+	}
+	p := hook.payloads[0]
+	if p.Alert != "errors" || p.Count != 3 || p.Window != "1m0s" || p.Message != "e4" || p.Level != "ERROR" || p.Logger != "com.foo.Service" {
+		t.Errorf("Unexpected payload: %+v", p)
+	}
+}
+
+func TestAlerter_WebhookError(t *testing.T) {
+	hook := newWebhook(t, http.StatusInternalServerError)
+
+	rule, _ := ParseRule("when=level==ERROR; webhook="+hook.URL, 0)
+	a := New([]*Rule{rule})
+	var errors strings.Builder
+	a.Errors = &errors
+
+	a.Check(msg("ERROR", "boom", 0), time.Now())
+	a.Wait()
+
+	if !strings.Contains(errors.String(), "alert alert 1: webhook failed: 500 Internal Server Error") {
+		t.Errorf("Expected the failed webhook to be reported, got: %q", errors.String())
+	}
+}
+
+func TestAlerter_Exec(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "alert.txt")
+
+	rule, err := ParseRule(`when=msg contains "timeout"; debounce=0s; exec=echo "$ALERT_NAME $ALERT_COUNT $ALERT_MESSAGE" >> `+out+`; cat >> `+out, 1)
+	if err != nil {
+		t.Fatalf("Expected rule to be valid, got: %v", err)
+	}
+	a := New([]*Rule{rule})
+
+	a.Check(msg("WARN", "no match", 0), time.Now())
+	a.Check(msg("WARN", "read timeout", 1), time.Now())
+	a.Wait()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Expected the command to write %s, got: %v", out, err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || lines[0] != "alert 2 1 read timeout" || !strings.HasPrefix(lines[1], `{"alert":"alert 2","count":1,`) {
+		t.Errorf("Unexpected command output: %q", data)
+	}
+}
+
+func TestRule_Debounce(t *testing.T) {
+	rule, _ := ParseRule("when=level==ERROR; debounce=1m; exec=true", 0)
+
+	var fired []string
+	for _, m := range []*parser.LogMessage{msg("ERROR", "a", 0), msg("ERROR", "b", 30), msg("ERROR", "c", 60), msg("ERROR", "d", 61)} {
+		if _, ok := rule.check(m, m.Time); ok {
+			fired = append(fired, m.Message)
+		}
+	}
+
+	if strings.Join(fired, ",") != "a,c" {
+		t.Errorf("Expected alerts for a and c, got %v", fired)
+	}
+}
+
+func TestParseRule_Errors(t *testing.T) {
+	tests := []struct {
+		spec     string
+		errorMsg string
+	}{
+		{"exec=true", "missing condition (when=...)"},
+		{"when=level>=ERROR", "missing action (exec=... or webhook=...)"},
+		{"when=level>=; exec=true", "invalid filter expression: unexpected end of expression"},
+		{"when=level>=ERROR; rate=5; exec=true", "invalid rate 5 (expected <count>/<duration>, e.g. 5/1m)"},
+		{"when=level>=ERROR; rate=0/1m; exec=true", "invalid rate 0/1m"},
+		{"when=level>=ERROR; debounce=soon; exec=true", `time: invalid duration "soon"`},
+		{"when=level>=ERROR; webhook=localhost:8080", "webhook must be an http or https URL"},
+		{"when=level>=ERROR; email=me; exec=true", "unknown key email"},
+		{"when=level>=ERROR; oops; exec=true", `expected key=value, got "oops"`},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseRule(tt.spec, 0)
+		if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+			t.Errorf("Rule %q: expected error containing %q, got: %v", tt.spec, tt.errorMsg, err)
+		}
+	}
+}
+
+func TestParseRule_QuotedSemicolons(t *testing.T) {
+	rule, err := ParseRule(`name=x; when=msg contains "a;b"; exec=echo 1; echo 2`, 0)
+	if err != nil {
+		t.Fatalf("Expected rule to be valid, got: %v", err)
+	}
+	if !rule.When(&parser.LogMessage{Message: "xa;bx"}) {
+		t.Error("Expected semicolon within quotes to be part of the condition")
+	}
+	if rule.Exec != "echo 1; echo 2" {
+		t.Errorf("Expected command to extend to the end of the rule, got %q", rule.Exec)
+	}
+}
@@ -5,11 +5,48 @@
 
 package config
 
+import "time"
+
 // Config holds the application configuration flags
 type Config struct {
 	Level          string
+	LoggerLevels   []string
+	Include        []string
 	Exclude        []string
+	Sources        []string
+	Grep           []string
+	GrepV          []string
+	Count          bool
+	BeforeContext  int
+	AfterContext   int
+	Context        int
+	ContextBy      string
+	Dedupe         bool
+	DedupeWindow   time.Duration
+	Summary        bool
+	SummaryEvery   time.Duration
+	Top            int
+	Alerts         []string
+	Since          string
+	Until          string
+	ExpandJSON     bool
+	ExpandDepth    int
+	Highlight      bool
+	Color          string
+	Theme          string
+	Themes         map[string]Theme // user-defined themes of the config files
 	TruncateRaw    bool
 	RemovePrefix   string
 	LoggerNameOnly bool
+	GroupTimeout   time.Duration
+	Where          string
+	Trace          string
+	Labels         []string
+	ReorderWindow  time.Duration
+	TimeFormat     string
+	Interactive    bool
+	Profile        string
+	Output         string
+	Template       string
+	ShowSource     bool
 }
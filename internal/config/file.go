@@ -0,0 +1,192 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the project-local configuration files, looked up in the working directory
+const (
+	LocalFileName     = ".cf-log-pretty.yaml"
+	LocalTOMLFileName = ".cf-log-pretty.toml"
+)
+
+// File is the content of a YAML or TOML configuration file. Options use the long names of the command line flags,
+// e.g.
+//
+//	level: INFO
+//	exclude-logger: [org.springframework.*]
+//	profiles:
+//	  prod:
+//	    remove-logger-prefix: com.mycompany.prod.
+//	themes:
+//	  mine:
+//	    levels: {ERROR: "#ff5f5f bold"}
+type File struct {
+	Options  map[string]any            `yaml:",inline" toml:"-"`
+	Profiles map[string]map[string]any `yaml:"profiles" toml:"profiles"`
+	Themes   map[string]Theme          `yaml:"themes" toml:"themes"`
+}
+
+// Theme defines the colors of the pretty output. Colors are space separated words, e.g. "red bold", "208"
+// (256 colors), "#ff8700" (truecolor) or "bg:blue". Colors that are not set are taken from the base theme.
+type Theme struct {
+	Base       string            `yaml:"base" toml:"base"` // built-in theme, default dark
+	Levels     map[string]string `yaml:"levels" toml:"levels"`
+	Timestamp  string            `yaml:"timestamp" toml:"timestamp"`
+	Logger     string            `yaml:"logger" toml:"logger"`
+	Sources    []string          `yaml:"sources" toml:"sources"` // palette for sources and input labels
+	StackTrace string            `yaml:"stacktrace" toml:"stacktrace"`
+}
+
+// Paths returns the configuration files in the order they are applied: the user's files in
+// $XDG_CONFIG_HOME/cf-log-pretty/config.yaml and config.toml and the project-local ones. TOML files are applied
+// after the YAML files of the same place.
+func Paths() []string {
+	var paths []string
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir, _ = os.UserConfigDir()
+	}
+	if dir != "" {
+		paths = append(paths, filepath.Join(dir, "cf-log-pretty", "config.yaml"), filepath.Join(dir, "cf-log-pretty", "config.toml"))
+	}
+
+	return append(paths, LocalFileName, LocalTOMLFileName)
+}
+
+// LoadFile reads the configuration file at path. A missing file is not an error and returns nil.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{}
+	if filepath.Ext(path) == ".toml" {
+		err = unmarshalTOML(data, file)
+	} else {
+		err = yaml.Unmarshal(data, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// unmarshalTOML decodes a TOML configuration file. As TOML has no inline tables, the options are the top-level keys
+// other than profiles and themes.
+func unmarshalTOML(data []byte, file *File) error {
+	if err := toml.Unmarshal(data, file); err != nil {
+		return err
+	}
+	if err := toml.Unmarshal(data, &file.Options); err != nil {
+		return err
+	}
+	delete(file.Options, "profiles")
+	delete(file.Options, "themes")
+	return nil
+}
+
+// untrustedOptions are the options that must not be set by the project-local file, as it comes with the project
+// instead of from the user. Alerts run commands and send logs to webhooks.
+var untrustedOptions = []string{"alert"}
+
+// checkTrusted returns an error if the project-local file sets an option of untrustedOptions
+func checkTrusted(path string, file *File) error {
+	if name := filepath.Base(path); name != LocalFileName && name != LocalTOMLFileName {
+		return nil
+	}
+
+	optionSets := []map[string]any{file.Options}
+	for _, name := range slices.Sorted(maps.Keys(file.Profiles)) {
+		optionSets = append(optionSets, file.Profiles[name])
+	}
+	for _, options := range optionSets {
+		for _, name := range untrustedOptions {
+			if _, ok := options[name]; ok {
+				return fmt.Errorf("option %s is not allowed in the project-local config file %s (use the command line or the user's config file)", name, path)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadOptions merges the options of the files at paths, later files overriding earlier ones.
+// The options of the given profile are applied on top. Without profile, the "profile" option of the files is used.
+func LoadOptions(paths []string, profile string) (map[string]any, error) {
+	options := map[string]any{}
+	profiles := map[string]map[string]any{}
+
+	for _, path := range paths {
+		file, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if file == nil {
+			continue
+		}
+		if err := checkTrusted(path, file); err != nil {
+			return nil, err
+		}
+
+		maps.Copy(options, file.Options)
+		for name, values := range file.Profiles {
+			if profiles[name] == nil {
+				profiles[name] = map[string]any{}
+			}
+			maps.Copy(profiles[name], values)
+		}
+	}
+
+	if profile == "" {
+		profile, _ = options["profile"].(string)
+	}
+	delete(options, "profile")
+
+	if profile != "" {
+		values, ok := profiles[profile]
+		if !ok && len(profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile: %s (no profiles defined in %s)", profile, strings.Join(paths, ", "))
+		}
+		if !ok {
+			available := slices.Sorted(maps.Keys(profiles))
+			return nil, fmt.Errorf("unknown profile: %s (available: %s)", profile, strings.Join(available, ", "))
+		}
+		maps.Copy(options, values)
+	}
+
+	return options, nil
+}
+
+// LoadThemes returns the themes defined in the files at paths. Themes of later files replace those of the same name.
+func LoadThemes(paths []string) (map[string]Theme, error) {
+	themes := map[string]Theme{}
+	for _, path := range paths {
+		file, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			maps.Copy(themes, file.Themes)
+		}
+	}
+	return themes, nil
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// helper to write a config file into a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadOptions(t *testing.T) {
+	global := writeConfig(t, `
+level: INFO
+remove-logger-prefix: com.mycompany.prod.
+profiles:
+  quiet:
+    level: WARN
+    exclude-logger: [org.springframework.*]
+  local:
+    remove-logger-prefix: com.mycompany.dev.
+`)
+	local := writeConfig(t, `
+level: DEBUG
+profiles:
+  quiet:
+    truncate-raw: true
+`)
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+
+	tests := []struct {
+		name     string
+		paths    []string
+		profile  string
+		expected map[string]any
+	}{
+		{
+			"No files",
+			[]string{missing},
+			"",
+			map[string]any{},
+		},
+		{
+			"Global file only",
+			[]string{global, missing},
+			"",
+			map[string]any{"level": "INFO", "remove-logger-prefix": "com.mycompany.prod."},
+		},
+		{
+			"Local file overrides global file",
+			[]string{global, local},
+			"",
+			map[string]any{"level": "DEBUG", "remove-logger-prefix": "com.mycompany.prod."},
+		},
+		{
+			"Profiles of both files are merged and override options",
+			[]string{global, local},
+			"quiet",
+			map[string]any{
+				"level":                "WARN",
+				"remove-logger-prefix": "com.mycompany.prod.",
+				"exclude-logger":       []any{"org.springframework.*"},
+				"truncate-raw":         true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := LoadOptions(tt.paths, tt.profile)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(options, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, options)
+			}
+		})
+	}
+}
+
+func TestLoadOptions_TOML(t *testing.T) {
+	global := writeConfig(t, "level: INFO\ntruncate-raw: true\n")
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+level = "WARN"
+exclude-logger = ["org.springframework.*", "com.zaxxer.hikari.*"]
+group-timeout = "1s"
+top = 5
+
+[profiles.quiet]
+level = "ERROR"
+
+[themes.mine]
+base = "light"
+levels = { ERROR = "#ff5f5f bold" }
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	options, err := LoadOptions([]string{global, path}, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := map[string]any{
+		"level":          "WARN",
+		"truncate-raw":   true,
+		"exclude-logger": []any{"org.springframework.*", "com.zaxxer.hikari.*"},
+		"group-timeout":  "1s",
+		"top":            int64(5),
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("Expected %v, got %v", expected, options)
+	}
+
+	options, _ = LoadOptions([]string{path}, "quiet")
+	if options["level"] != "ERROR" {
+		t.Errorf("Expected profile of the TOML file to be applied, got %v", options)
+	}
+
+	themes, _ := LoadThemes([]string{path})
+	if !reflect.DeepEqual(themes, map[string]Theme{"mine": {Base: "light", Levels: map[string]string{"ERROR": "#ff5f5f bold"}}}) {
+		t.Errorf("Unexpected themes: %v", themes)
+	}
+}
+
+func TestLoadOptions_DefaultProfile(t *testing.T) {
+	path := writeConfig(t, `
+profile: quiet
+profiles:
+  quiet:
+    level: WARN
+  loud:
+    level: TRACE
+`)
+
+	options, err := LoadOptions([]string{path}, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(options, map[string]any{"level": "WARN"}) {
+		t.Errorf("Expected default profile to be applied, got %v", options)
+	}
+
+	options, _ = LoadOptions([]string{path}, "loud")
+	if !reflect.DeepEqual(options, map[string]any{"level": "TRACE"}) {
+		t.Errorf("Expected given profile to win over default profile, got %v", options)
+	}
+}
+
+// helper to write a project-local config file into a temporary directory
+func writeLocalConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), LocalFileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadOptions_Errors(t *testing.T) {
+	withProfiles := writeConfig(t, "profiles:\n  b: {}\n  a: {}\n")
+	withoutProfiles := writeConfig(t, "level: INFO\n")
+	invalid := writeConfig(t, "level: [INFO\n")
+	invalidTOML := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(invalidTOML, []byte("level = [\"INFO\"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	localTOMLAlert := filepath.Join(t.TempDir(), LocalTOMLFileName)
+	if err := os.WriteFile(localTOMLAlert, []byte("alert = [\"when=level>=TRACE; exec=touch /tmp/pwned\"]\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	localAlert := writeLocalConfig(t, "alert: [\"when=level>=TRACE; exec=touch /tmp/pwned\"]\n")
+	localProfileAlert := writeLocalConfig(t, "profiles:\n  ci:\n    alert: [\"when=level>=ERROR; webhook=http://localhost/hook\"]\n")
+
+	tests := []struct {
+		name     string
+		paths    []string
+		profile  string
+		errorMsg string
+	}{
+		{"Unknown profile", []string{withProfiles}, "c", "unknown profile: c (available: a, b)"},
+		{"No profiles defined", []string{withoutProfiles}, "c", "unknown profile: c (no profiles defined in " + withoutProfiles + ")"},
+		{"Invalid YAML", []string{invalid}, "", "invalid config file " + invalid},
+		{"Invalid TOML", []string{invalidTOML}, "", "invalid config file " + invalidTOML},
+		{"Alert in project-local TOML file", []string{localTOMLAlert}, "", "option alert is not allowed in the project-local config file " + localTOMLAlert},
+		{"Alert in project-local file", []string{withoutProfiles, localAlert}, "", "option alert is not allowed in the project-local config file " + localAlert},
+		{"Alert in profile of project-local file", []string{localProfileAlert}, "", "option alert is not allowed in the project-local config file " + localProfileAlert},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadOptions(tt.paths, tt.profile)
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if !strings.HasPrefix(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error message %q but got %q", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadOptions_AlertInUserFile(t *testing.T) {
+	path := writeConfig(t, "alert: [\"when=level>=ERROR; exec=notify-send error\"]\n")
+
+	options, err := LoadOptions([]string{path}, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(options, map[string]any{"alert": []any{"when=level>=ERROR; exec=notify-send error"}}) {
+		t.Errorf("Expected alert of the user's config file, got %v", options)
+	}
+}
+
+func TestLoadThemes(t *testing.T) {
+	global := writeConfig(t, `
+level: INFO
+themes:
+  mine:
+    base: light
+    levels: {ERROR: "#ff5f5f bold"}
+  other:
+    logger: blue
+`)
+	local := writeConfig(t, `
+themes:
+  mine:
+    timestamp: gray
+`)
+
+	themes, err := LoadThemes([]string{global, local, filepath.Join(t.TempDir(), "missing.yaml")})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := map[string]Theme{
+		"mine":  {Timestamp: "gray"},
+		"other": {Logger: "blue"},
+	}
+	if !reflect.DeepEqual(themes, expected) {
+		t.Errorf("Expected %v, got %v", expected, themes)
+	}
+
+	options, _ := LoadOptions([]string{global}, "")
+	if !reflect.DeepEqual(options, map[string]any{"level": "INFO"}) {
+		t.Errorf("Expected themes not to be options, got %v", options)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/user/.xdg")
+
+	expected := []string{"/home/user/.xdg/cf-log-pretty/config.yaml", "/home/user/.xdg/cf-log-pretty/config.toml", ".cf-log-pretty.yaml", ".cf-log-pretty.toml"}
+	if got := Paths(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
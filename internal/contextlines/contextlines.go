@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package contextlines
+
+import (
+	"container/list"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// Keys restricting the context of a match to related messages
+const (
+	ByAll         = ""
+	ByInstance    = "instance"
+	ByCorrelation = "correlation"
+)
+
+// MaxStreams limits the number of instances or correlation IDs whose preceding messages are kept. The least recently
+// seen ones are dropped first, unless they still have following messages to emit.
+const MaxStreams = 1000
+
+// Keys lists the supported values of the --context-by flag
+var Keys = []string{ByInstance, ByCorrelation}
+
+// Line is a message emitted by the Context stage, or a separator between groups of messages that are not adjacent
+type Line struct {
+	Msg       *parser.LogMessage // nil for separators
+	Context   bool               // the message is shown as context of a match
+	Separator bool
+}
+
+// Context emits the messages passing Match together with Before preceding and After following messages,
+// like grep -B/-A. With By, only messages of the same instance or correlation ID are used as context.
+type Context struct {
+	Before int
+	After  int
+	By     string
+	Match  func(*parser.LogMessage) bool
+}
+
+// stream holds the state of the messages sharing a key
+type stream struct {
+	buffer      []*parser.LogMessage // the last Before messages not emitted yet
+	first       int                  // sequence number of buffer[0]
+	next        int                  // sequence number of the next message
+	lastEmitted int                  // sequence number of the last emitted message, -1 if none
+	remaining   int                  // following messages still to emit as context
+	key         string
+	element     *list.Element // of the stream in the recently used list
+}
+
+func New(before int, after int, by string, match func(*parser.LogMessage) bool) *Context {
+	return &Context{
+		Before: before,
+		After:  after,
+		By:     by,
+		Match:  match,
+	}
+}
+
+// Run consumes messages from in and emits the matching messages with their context on the returned channel.
+// A separator precedes every group of messages that doesn't directly follow the previously emitted messages.
+func (c *Context) Run(in <-chan *parser.LogMessage) <-chan Line {
+	out := make(chan Line)
+
+	go func() {
+		defer close(out)
+
+		streams := map[string]*stream{}
+		recent := list.New() // streams, most recently used first
+		emittedAny := false
+
+		emit := func(s *stream, seq int, msg *parser.LogMessage, context bool) {
+			if emittedAny && seq != s.lastEmitted+1 {
+				out <- Line{Separator: true}
+			}
+			out <- Line{Msg: msg, Context: context}
+			s.lastEmitted = seq
+			emittedAny = true
+		}
+
+		for msg := range in {
+			key := c.key(msg)
+			s, ok := streams[key]
+			if ok {
+				recent.MoveToFront(s.element)
+			} else {
+				s = &stream{lastEmitted: -1, key: key}
+				s.element = recent.PushFront(s)
+				streams[key] = s
+				if len(streams) > MaxStreams {
+					evict(streams, recent)
+				}
+			}
+			seq := s.next
+			s.next++
+
+			switch {
+			case c.Match(msg):
+				for i, buffered := range s.buffer {
+					emit(s, s.first+i, buffered, true)
+				}
+				s.buffer = s.buffer[:0]
+				emit(s, seq, msg, false)
+				s.remaining = c.After
+
+			case s.remaining > 0:
+				emit(s, seq, msg, true)
+				s.remaining--
+
+			case c.Before > 0:
+				if len(s.buffer) == c.Before {
+					s.buffer = append(s.buffer[:0], s.buffer[1:]...)
+				}
+				s.buffer = append(s.buffer, msg)
+				s.first = seq - len(s.buffer) + 1
+			}
+		}
+	}()
+
+	return out
+}
+
+// evict drops the least recently used stream that has no following messages to emit
+func evict(streams map[string]*stream, recent *list.List) {
+	for e := recent.Back(); e != nil; e = e.Prev() {
+		if s := e.Value.(*stream); s.remaining == 0 {
+			recent.Remove(e)
+			delete(streams, s.key)
+			return
+		}
+	}
+}
+
+// key returns the key of the messages msg can be context of
+func (c *Context) key(msg *parser.LogMessage) string {
+	switch c.By {
+	case ByInstance:
+		return msg.Label + "\x00" + msg.Source
+	case ByCorrelation:
+		if msg.CorrelationID != "" {
+			return msg.CorrelationID
+		}
+		return msg.VcapRequestID
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package contextlines
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// helper to run messages "<source> <correlation id> <message>" through a context stage, matching messages
+// starting with "ERR". The result lists the messages, context lines prefixed with "~" and separators as "--".
+func run(t *testing.T, c *Context, lines ...string) []string {
+	t.Helper()
+
+	c.Match = func(msg *parser.LogMessage) bool {
+		return strings.HasPrefix(msg.Message, "ERR")
+	}
+
+	in := make(chan *parser.LogMessage)
+	go func() {
+		defer close(in)
+		for _, line := range lines {
+			parts := strings.SplitN(line, " ", 3)
+			in <- &parser.LogMessage{Source: parts[0], CorrelationID: parts[1], Message: parts[2]}
+		}
+	}()
+
+	var result []string
+	for line := range c.Run(in) {
+		switch {
+		case line.Separator:
+			result = append(result, "--")
+		case line.Context:
+			result = append(result, "~"+line.Msg.Message)
+		default:
+			result = append(result, line.Msg.Message)
+		}
+	}
+	return result
+}
+
+func TestContext_Run(t *testing.T) {
+	var lines []string
+	for _, message := range []string{"a", "b", "c", "ERR1", "d", "e", "f", "g", "ERR2", "h", "ERR3", "i", "j"} {
+		lines = append(lines, "WEB/0 - "+message)
+	}
+
+	tests := []struct {
+		name     string
+		before   int
+		after    int
+		expected string
+	}{
+		{"No context", 0, 0, "ERR1 -- ERR2 -- ERR3"},
+		{"Before", 2, 0, "~b ~c ERR1 -- ~f ~g ERR2 ~h ERR3"},
+		{"After", 0, 1, "ERR1 ~d -- ERR2 ~h ERR3 ~i"},
+		{"Before and after", 1, 1, "~c ERR1 ~d -- ~g ERR2 ~h ERR3 ~i"},
+		{"Overlapping groups", 3, 3, "~a ~b ~c ERR1 ~d ~e ~f ~g ERR2 ~h ERR3 ~i ~j"},
+		{"More context than messages", 10, 0, "~a ~b ~c ERR1 ~d ~e ~f ~g ERR2 ~h ERR3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := strings.Join(run(t, New(tt.before, tt.after, ByAll, nil), lines...), " ")
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestContext_RunByInstance(t *testing.T) {
+	result := strings.Join(run(t, New(1, 1, ByInstance, nil),
+		"WEB/0 - a0",
+		"WEB/1 - a1",
+		"WEB/0 - ERR0",
+		"WEB/1 - b1",
+		"WEB/0 - b0",
+		"WEB/1 - c1",
+	), " ")
+
+	if expected := "~a0 ERR0 ~b0"; result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestContext_RunByCorrelation(t *testing.T) {
+	result := strings.Join(run(t, New(2, 0, ByCorrelation, nil),
+		"WEB/0 req1 a",
+		"WEB/1 req2 b",
+		"RTR/0 req1 c",
+		"WEB/0 req2 d",
+		"WEB/1 req1 ERR",
+	), " ")
+
+	if expected := "~a ~c ERR"; result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestContext_RunEvictsStreams(t *testing.T) {
+	lines := []string{"WEB/0 old a", "WEB/0 open ERR 1"}
+	for i := range MaxStreams {
+		lines = append(lines, fmt.Sprintf("WEB/0 req%d b", i))
+	}
+	lines = append(lines, "WEB/0 old ERR 2", "WEB/0 open c")
+
+	result := run(t, New(1, 1, ByCorrelation, nil), lines...)
+
+	// The context of the least recently used request is dropped, the pending context of a match is kept
+	if expected := "ERR 1 ERR 2 ~c"; strings.Join(result, " ") != expected {
+		t.Errorf("Expected %q, got %q", expected, strings.Join(result, " "))
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package dedupe
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// MaxAge limits how long duplicates are collapsed, so a steady flood is still reported regularly
+const MaxAge = time.Minute
+
+var (
+	uuidRegex   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	hexRegex    = regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b|\b[0-9a-f]{8,}\b`)
+	numberRegex = regexp.MustCompile(`\d+(\.\d+)?`)
+)
+
+// Deduper collapses consecutive duplicates of a message into the first one
+type Deduper struct {
+	Window time.Duration
+}
+
+type pendingMessage struct {
+	msg      *parser.LogMessage
+	key      string
+	first    time.Time // of the first duplicate, log time if known, otherwise when it was received
+	last     time.Time // of the last duplicate, like first
+	started  time.Time // when the first duplicate was received
+	lastSeen time.Time // when the last duplicate was received
+}
+
+// within reports whether msg, received at now, is within Window of the last duplicate and within MaxAge of the
+// first one. Log times are compared if known, so files are collapsed like live streams.
+func (p *pendingMessage) within(msg *parser.LogMessage, now time.Time, window time.Duration) bool {
+	if !msg.Time.IsZero() && !p.msg.Time.IsZero() {
+		return msg.Time.Sub(p.last) < window && msg.Time.Sub(p.first) < MaxAge
+	}
+	return now.Sub(p.lastSeen) < window && now.Sub(p.started) < MaxAge
+}
+
+func New(window time.Duration) *Deduper {
+	return &Deduper{
+		Window: window,
+	}
+}
+
+// Run consumes messages from in and emits them on the returned channel with consecutive duplicates collapsed,
+// counted in Repeated of the first one. Messages more than Window after the previous duplicate or MaxAge after the
+// first one are no duplicates. A held back message is emitted when a different message arrives, after Window
+// without further duplicate, after MaxAge, or when in is closed.
+func (d *Deduper) Run(in <-chan *parser.LogMessage) <-chan *parser.LogMessage {
+	out := make(chan *parser.LogMessage)
+
+	go func() {
+		defer close(out)
+
+		var pending *pendingMessage
+		flush := func() {
+			if pending == nil {
+				return
+			}
+			out <- pending.msg
+			pending = nil
+		}
+
+		timer := time.NewTimer(d.Window)
+		defer timer.Stop()
+
+		for {
+			select {
+			case msg, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+
+				now := time.Now()
+				key := Key(msg)
+				if pending != nil && pending.key == key && pending.within(msg, now, d.Window) {
+					pending.msg.Repeated++
+					pending.last, pending.lastSeen = logTime(msg, now), now
+					pending.msg.RepeatedFor = pending.last.Sub(pending.first)
+					continue
+				}
+
+				flush()
+				msg.Repeated = 1
+				first := logTime(msg, now)
+				pending = &pendingMessage{msg: msg, key: key, first: first, last: first, started: now, lastSeen: now}
+
+			case now := <-timer.C:
+				// Received times, so live streams are printed without waiting for the next message
+				if pending != nil && (now.Sub(pending.lastSeen) >= d.Window || now.Sub(pending.started) >= MaxAge) {
+					flush()
+				}
+				timer.Reset(d.Window)
+			}
+		}
+	}()
+
+	return out
+}
+
+// Key identifies duplicates: messages of the same input, level and logger whose message and stack trace only differ
+// in numbers, UUIDs and hexadecimal IDs
+func Key(msg *parser.LogMessage) string {
+	parts := []string{msg.Label, msg.Level, msg.Logger, Normalize(msg.Message)}
+	for _, line := range msg.StackTrace {
+		parts = append(parts, Normalize(line))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// Normalize masks the parts of a message that usually differ between repetitions: UUIDs, hexadecimal IDs and numbers
+func Normalize(message string) string {
+	message = uuidRegex.ReplaceAllString(message, "<uuid>")
+	message = hexRegex.ReplaceAllString(message, "<hex>")
+	return numberRegex.ReplaceAllString(message, "<n>")
+}
+
+// logTime returns the time of msg, or now for messages without time
+func logTime(msg *parser.LogMessage, now time.Time) time.Time {
+	if msg.Time.IsZero() {
+		return now
+	}
+	return msg.Time
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package dedupe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// helper to build a LogMessage logged the given number of seconds after a fixed time
+func msg(level string, message string, second int) *parser.LogMessage {
+	return &parser.LogMessage{
+		Time:    time.Date(2024, 1, 1, 12, 0, second, 0, time.UTC),
+		Level:   level,
+		Logger:  "com.foo.Health",
+		Message: message,
+	}
+}
+
+// helper to run messages through a deduper and collect the result
+func run(window time.Duration, messages ...*parser.LogMessage) []*parser.LogMessage {
+	in := make(chan *parser.LogMessage)
+	go func() {
+		defer close(in)
+		for _, m := range messages {
+			in <- m
+		}
+	}()
+
+	var result []*parser.LogMessage
+	for m := range New(window).Run(in) {
+		result = append(result, m)
+	}
+	return result
+}
+
+func TestDeduper_CollapsesRepetitions(t *testing.T) {
+	result := run(time.Minute,
+		msg("INFO", "health check 1 ok in 13ms", 0),
+		msg("INFO", "health check 2 ok in 9ms", 5),
+		msg("INFO", "health check 3 ok in 11ms", 12),
+		msg("WARN", "slow request", 13),
+		msg("INFO", "health check 4 ok in 10ms", 14),
+	)
+
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(result))
+	}
+	if result[0].Message != "health check 1 ok in 13ms" || result[0].Repeated != 3 || result[0].RepeatedFor != 12*time.Second {
+		t.Errorf("Expected first message with 3 repetitions in 12s, got %q (×%d in %s)", result[0].Message, result[0].Repeated, result[0].RepeatedFor)
+	}
+	if result[1].Message != "slow request" || result[1].Repeated != 1 {
+		t.Errorf("Expected single message, got %q (×%d)", result[1].Message, result[1].Repeated)
+	}
+	if result[2].Repeated != 1 {
+		t.Errorf("Expected repetition after a different message to start over, got ×%d", result[2].Repeated)
+	}
+}
+
+func TestDeduper_WindowInLogTime(t *testing.T) {
+	// Files are read at once, so only the log times tell whether duplicates are within the window
+	messages := []*parser.LogMessage{
+		msg("INFO", "health ok 1", 0),
+		msg("INFO", "health ok 2", 3),
+		msg("INFO", "health ok 3", 7200),
+	}
+	// A duplicate every 4 seconds for more than MaxAge
+	for second := 14400; second <= 14400+int(MaxAge/time.Second); second += 4 {
+		messages = append(messages, msg("INFO", "health ok", second))
+	}
+
+	result := run(5*time.Second, messages...)
+
+	expected := []int{2, 1, 15, 1}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d", len(expected), len(result))
+	}
+	for i, repeated := range expected {
+		if result[i].Repeated != repeated {
+			t.Errorf("Expected message %d (%q) to be repeated %d times, got %d", i, result[i].Message, repeated, result[i].Repeated)
+		}
+	}
+	if result[0].RepeatedFor != 3*time.Second {
+		t.Errorf("Expected repetitions within 3s, got %s", result[0].RepeatedFor)
+	}
+}
+
+func TestDeduper_DifferentLevelOrLogger(t *testing.T) {
+	other := msg("INFO", "health check 2 ok", 1)
+	other.Logger = "com.foo.Other"
+
+	result := run(time.Minute,
+		msg("INFO", "health check 1 ok", 0),
+		other,
+		msg("WARN", "health check 3 ok", 2),
+	)
+
+	if len(result) != 3 {
+		t.Errorf("Expected messages of different loggers and levels to be kept, got %d", len(result))
+	}
+}
+
+func TestDeduper_FlushesAfterWindow(t *testing.T) {
+	in := make(chan *parser.LogMessage)
+	out := New(20 * time.Millisecond).Run(in)
+
+	in <- msg("INFO", "health check 1 ok", 0)
+	in <- msg("INFO", "health check 2 ok", 0)
+
+	select {
+	case m := <-out:
+		if m.Repeated != 2 {
+			t.Errorf("Expected 2 repetitions, got %d", m.Repeated)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected message to be flushed after the window")
+	}
+
+	close(in)
+	if _, ok := <-out; ok {
+		t.Error("Expected no further messages")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"retry 3 of 10 after 1.5s", "retry <n> of <n> after <n>s"},
+		{"order d2b5f4a0-8c1e-4c57-a3ab-6a3c0e8f2d11 created", "order <uuid> created"},
+		{"trace 5f0c6a1e8c1e4c57 at 0x7ffd", "trace <hex> at <hex>"},
+		{"no numbers here", "no numbers here"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.expected {
+			t.Errorf("Normalize(%q): expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
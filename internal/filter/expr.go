@@ -0,0 +1,339 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// Expr is a compiled filter expression, evaluated per log message
+type Expr func(msg *parser.LogMessage) bool
+
+// Compile parses a filter expression like
+//
+//	level>=WARN && logger~"com.foo.*" && correlation_id=="abc" && msg contains "timeout"
+//
+// Comparisons have the form <field> <operator> <value>, where field is any name understood by
+// parser.LogMessage.Field. Supported operators are ==, !=, <, <=, >, >= (log levels are compared by
+// priority, numbers numerically), ~ and !~ (wildcard match, "*" matches any sequence), contains and
+// matches (regular expression). Comparisons can be combined with &&, ||, ! and parentheses.
+// Values can be quoted with double quotes and must be quoted if they contain spaces or operators.
+func Compile(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// symbols lists all operator-like tokens, longer ones first so "!=" wins over "!"
+var symbols = []struct {
+	text string
+	kind tokenKind
+}{
+	{"&&", tokenAnd},
+	{"||", tokenOr},
+	{"==", tokenOperator},
+	{"!=", tokenOperator},
+	{"!~", tokenOperator},
+	{">=", tokenOperator},
+	{"<=", tokenOperator},
+	{">", tokenOperator},
+	{"<", tokenOperator},
+	{"~", tokenOperator},
+	{"!", tokenNot},
+	{"(", tokenLParen},
+	{")", tokenRParen},
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+
+outer:
+	for i < len(input) {
+		c := input[i]
+
+		if c == ' ' || c == '\t' {
+			i++
+			continue
+		}
+
+		if c == '"' {
+			var sb strings.Builder
+			start := i
+			i++
+			for i < len(input) && input[i] != '"' {
+				if input[i] == '\\' && i+1 < len(input) {
+					i++
+				}
+				sb.WriteByte(input[i])
+				i++
+			}
+			if i >= len(input) {
+				return nil, fmt.Errorf("invalid filter expression at position %d: unterminated string", start+1)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+			continue
+		}
+
+		for _, sym := range symbols {
+			if strings.HasPrefix(input[i:], sym.text) {
+				tokens = append(tokens, token{kind: sym.kind, text: sym.text, pos: i})
+				i += len(sym.text)
+				continue outer
+			}
+		}
+
+		start := i
+		for i < len(input) && isWordChar(rune(input[i])) {
+			i++
+		}
+		if start == i {
+			return nil, fmt.Errorf("invalid filter expression at position %d: unexpected character %q", i+1, c)
+		}
+
+		word := input[start:i]
+		switch word {
+		case "contains", "matches":
+			tokens = append(tokens, token{kind: tokenOperator, text: word, pos: start})
+		default:
+			tokens = append(tokens, token{kind: tokenWord, text: word, pos: start})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-*#/:$@+", r)
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) errorf(t token, format string, a ...interface{}) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("invalid filter expression: unexpected end of expression")
+	}
+	return fmt.Errorf("invalid filter expression at position %d: %s", t.pos+1, fmt.Sprintf(format, a...))
+}
+
+func (p *exprParser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(msg *parser.LogMessage) bool { return l(msg) || right(msg) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(msg *parser.LogMessage) bool { return l(msg) && right(msg) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (Expr, error) {
+	switch t := p.peek(); t.kind {
+	case tokenNot:
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(msg *parser.LogMessage) bool { return !inner(msg) }, nil
+
+	case tokenLParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorf(closing, "expected \")\" but got %q", closing.text)
+		}
+		return inner, nil
+
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *exprParser) parseComparison() (Expr, error) {
+	field := p.next()
+	if field.kind != tokenWord {
+		return nil, p.errorf(field, "expected field name but got %q", field.text)
+	}
+
+	op := p.next()
+	if op.kind != tokenOperator {
+		return nil, p.errorf(op, "expected operator after %q but got %q", field.text, op.text)
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, p.errorf(value, "expected value after %q but got %q", op.text, value.text)
+	}
+
+	return compileComparison(strings.ToLower(field.text), op, value, p)
+}
+
+func compileComparison(field string, op token, value token, p *exprParser) (Expr, error) {
+	lookup := func(msg *parser.LogMessage) string {
+		v, _ := msg.Field(field)
+		return v
+	}
+
+	switch op.text {
+	case "contains":
+		return func(msg *parser.LogMessage) bool { return strings.Contains(lookup(msg), value.text) }, nil
+
+	case "matches":
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, p.errorf(value, "invalid regular expression: %v", err)
+		}
+		return func(msg *parser.LogMessage) bool { return re.MatchString(lookup(msg)) }, nil
+
+	case "~", "!~":
+		re := wildcardRegex(value.text)
+		negate := op.text == "!~"
+		return func(msg *parser.LogMessage) bool { return re.MatchString(lookup(msg)) != negate }, nil
+	}
+
+	// Ordering operators: log levels by priority, numbers numerically, everything else as strings
+	if field == "level" {
+		want, ok := LevelPriority[strings.ToUpper(value.text)]
+		if !ok {
+			return nil, p.errorf(value, "invalid log level %q (allowed: TRACE, DEBUG, INFO, WARN, ERROR)", value.text)
+		}
+		return func(msg *parser.LogMessage) bool {
+			return compareOrdered(levelPriority(msg.Level), want, op.text)
+		}, nil
+	}
+
+	if want, err := strconv.ParseFloat(value.text, 64); err == nil {
+		return func(msg *parser.LogMessage) bool {
+			got, err := strconv.ParseFloat(lookup(msg), 64)
+			if err != nil {
+				return op.text == "!="
+			}
+			return compareOrdered(got, want, op.text)
+		}, nil
+	}
+
+	return func(msg *parser.LogMessage) bool {
+		return compareOrdered(lookup(msg), value.text, op.text)
+	}, nil
+}
+
+func compareOrdered[T int | float64 | string](got, want T, op string) bool {
+	switch op {
+	case "==":
+		return got == want
+	case "!=":
+		return got != want
+	case "<":
+		return got < want
+	case "<=":
+		return got <= want
+	case ">":
+		return got > want
+	case ">=":
+		return got >= want
+	}
+	return false
+}
+
+// levelPriority returns the priority of a message level, treating unknown levels like "-----"
+func levelPriority(level string) int {
+	prio, ok := LevelPriority[strings.ToUpper(level)]
+	if !ok {
+		return LevelPriority["-----"]
+	}
+	return prio
+}
+
+// wildcardRegex converts a pattern where "*" matches any sequence into an anchored regular expression
+func wildcardRegex(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+func TestCompile_Evaluate(t *testing.T) {
+	m := &parser.LogMessage{
+		Level:         "WARN",
+		Logger:        "com.foo.orders.OrderService",
+		Message:       "Request timeout after 30s",
+		Source:        "APP/PROC/WEB/1",
+		CorrelationID: "abc",
+		CustomFields:  map[string]string{"order_id": "42"},
+		Fields: map[string]any{
+			"correlation_id":   "abc",
+			"response_time_ms": "15.5",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		expr        string
+		expectMatch bool
+	}{
+		{"Level greater or equal", "level>=WARN", true},
+		{"Level greater or equal lowercase", "level >= info", true},
+		{"Level greater", "level>WARN", false},
+		{"Level equal", `level=="WARN"`, true},
+		{"Logger wildcard", `logger~"com.foo.*"`, true},
+		{"Logger wildcard no match", `logger~"com.bar.*"`, false},
+		{"Source type", "source_type==APP", true},
+		{"Instance index", "instance==1", true},
+		{"Instance index numeric", "instance>=2", false},
+		{"Logger negated wildcard", `logger!~"com.bar.*"`, true},
+		{"Logger wildcard in the middle", `logger~"com.*.OrderService"`, true},
+		{"Field equal", `correlation_id=="abc"`, true},
+		{"Field not equal", `correlation_id!=abc`, false},
+		{"Message contains", `msg contains "timeout"`, true},
+		{"Message matches", `message matches "after \\d+s$"`, true},
+		{"Custom field", `order_id==42`, true},
+		{"Numeric comparison", `response_time_ms>10`, true},
+		{"Numeric comparison on missing field", `response_status>=500`, false},
+		{"Missing field equals empty", `tenant_id==""`, true},
+		{"And", `level>=WARN && logger~"com.foo.*" && correlation_id=="abc" && msg contains "timeout"`, true},
+		{"And with one false", `level>=WARN && msg contains "refused"`, false},
+		{"Or", `level==ERROR || msg contains "timeout"`, true},
+		{"Not", `!(level==ERROR)`, true},
+		{"Precedence of && over ||", `level==ERROR && msg contains "x" || source~"APP/*"`, true},
+		{"Parentheses", `level==ERROR && (msg contains "x" || source~"APP/*")`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Expected expression to compile, got: %v", err)
+			}
+
+			got := expr(m)
+			if got != tt.expectMatch {
+				t.Errorf("Expected match = %v, got %v", tt.expectMatch, got)
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		errorMsg string
+	}{
+		{"Missing operator", "level WARN", `invalid filter expression at position 7: expected operator after "level" but got "WARN"`},
+		{"Missing value", "level>=", "invalid filter expression: unexpected end of expression"},
+		{"Unterminated string", `msg contains "timeout`, "invalid filter expression at position 14: unterminated string"},
+		{"Invalid level", "level>=FATAL", `invalid filter expression at position 8: invalid log level "FATAL" (allowed: TRACE, DEBUG, INFO, WARN, ERROR)`},
+		{"Invalid regex", `msg matches "("`, "invalid filter expression at position 13: invalid regular expression"},
+		{"Missing closing parenthesis", "(level==INFO", "invalid filter expression: unexpected end of expression"},
+		{"Dangling operator", "level==INFO &&", "invalid filter expression: unexpected end of expression"},
+		{"Trailing token", "level==INFO)", `invalid filter expression at position 12: unexpected ")"`},
+		{"Unexpected character", "level==INFO & x", "invalid filter expression at position 13: unexpected character '&'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.expr)
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if !strings.HasPrefix(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error message %q but got %q", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestFilter_Matches_Where(t *testing.T) {
+	tests := []struct {
+		name        string
+		where       string
+		expectMatch bool
+	}{
+		{"No expression matches everything", "", true},
+		{"Matching expression", `logger~"com.sap.*"`, true},
+		{"Not matching expression", `logger~"com.foo.*"`, false},
+		{"Invalid expression matches nothing", "level>=", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(&config.Config{Level: "TRACE", Where: tt.where})
+
+			got := f.Matches(msg("INFO", "com.sap.test"))
+			if got != tt.expectMatch {
+				t.Errorf("Expected match = %v, got %v", tt.expectMatch, got)
+			}
+		})
+	}
+}
@@ -6,7 +6,12 @@
 package filter
 
 import (
+	"fmt"
+	"path"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/saschakiefer/cf-log-pretty/internal/config"
 	"github.com/saschakiefer/cf-log-pretty/internal/parser"
@@ -19,26 +24,80 @@ var LevelPriority = map[string]int{
 	"WARN":  4,
 	"ERROR": 5,
 	"-----": 6,
+	"OFF":   7, // only used as minimum level, hides everything
 }
 
 type Filter struct {
-	Level   string
-	Exclude []string
+	Level        string
+	LoggerLevels map[string]string // minimum levels per logger or package, overriding Level
+	Include      []string
+	Exclude      []string
+	Sources      []string
+	Grep         []*regexp.Regexp // at least one must match
+	GrepV        []*regexp.Regexp // none must match
+	Where        Expr
+	Since        *TimeBound
+	Until        *TimeBound
+
+	mu    sync.Mutex
+	first time.Time // time of the first log, which relative time bounds refer to
 }
 
 func New(cfg *config.Config) *Filter {
-	return &Filter{
+	f := &Filter{
 		Level:   strings.ToUpper(cfg.Level),
+		Include: cfg.Include,
 		Exclude: cfg.Exclude,
+		Sources: cfg.Sources,
+	}
+
+	// Invalid entries are skipped (validated upfront by the command)
+	f.LoggerLevels, _ = ParseLoggerLevels(cfg.LoggerLevels)
+
+	// Invalid patterns are skipped (validated upfront by the command)
+	for _, pattern := range cfg.Grep {
+		if re, err := CompileGrep(pattern); err == nil {
+			f.Grep = append(f.Grep, re)
+		}
+	}
+	for _, pattern := range cfg.GrepV {
+		if re, err := CompileGrep(pattern); err == nil {
+			f.GrepV = append(f.GrepV, re)
+		}
+	}
+
+	// Invalid times are ignored (validated upfront by the command)
+	now := time.Now()
+	if cfg.Since != "" {
+		f.Since, _ = ParseTimeBound(cfg.Since, now)
+	}
+	if cfg.Until != "" {
+		f.Until, _ = ParseTimeBound(cfg.Until, now)
 	}
+
+	if cfg.Where != "" {
+		where, err := Compile(cfg.Where)
+		if err != nil {
+			// Invalid expression → match nothing (validated upfront by the command)
+			where = func(*parser.LogMessage) bool { return false }
+		}
+		f.Where = where
+	}
+
+	return f
 }
 
 func (f *Filter) Matches(msg *parser.LogMessage) bool {
+	// Time range (checked first, so that the first log is seen for relative times)
+	if (f.Since != nil || f.Until != nil) && !f.inTimeRange(msg) {
+		return false
+	}
+
 	// Log Level
 	msgLevel := strings.ToUpper(msg.Level)
 
 	logPrio, okLog := LevelPriority[msgLevel]
-	filterPrio, okFilter := LevelPriority[f.Level]
+	filterPrio, okFilter := LevelPriority[f.loggerLevel(msg.Logger)]
 
 	if !okLog {
 		// Unknown log level → treat as lowest (fallback)
@@ -53,31 +112,185 @@ func (f *Filter) Matches(msg *parser.LogMessage) bool {
 		return false
 	}
 
-	// Exclude Logger
-	if len(f.Exclude) > 0 && f.matchesExcludedLogger(msg.Logger) {
+	// Include and exclude Logger (an excluded logger stays excluded even if it is included as well)
+	if len(f.Include) > 0 && !matchesLogger(f.Include, msg.Logger) {
+		return false
+	}
+	if len(f.Exclude) > 0 && matchesLogger(f.Exclude, msg.Logger) {
+		return false
+	}
+
+	// Sources
+	if len(f.Sources) > 0 && !f.matchesSources(msg.Source) {
+		return false
+	}
+
+	// Full-text search
+	if len(f.Grep) > 0 && !matchesGrep(f.Grep, msg) {
+		return false
+	}
+	if len(f.GrepV) > 0 && matchesGrep(f.GrepV, msg) {
+		return false
+	}
+
+	// Filter expression
+	if f.Where != nil && !f.Where(msg) {
 		return false
 	}
 
 	return true
 }
 
-// matchesExcludedLogger checks if the logger name matches any exclude pattern.
-// Patterns ending with "*" are treated as package prefixes (e.g., "com.foo.core.*" matches "com.foo.core.Service").
-// Patterns without "*" must match exactly.
-func (f *Filter) matchesExcludedLogger(logger string) bool {
-	for _, pattern := range f.Exclude {
-		if strings.HasSuffix(pattern, "*") {
-			// Package prefix matching
-			prefix := strings.TrimSuffix(pattern, "*")
-			if strings.HasPrefix(logger, prefix) {
-				return true
+// loggerLevel returns the minimum level for the logger: the level of the longest matching entry of LoggerLevels,
+// or Level if there is none
+func (f *Filter) loggerLevel(logger string) string {
+	level, longest := f.Level, -1
+	for name, l := range f.LoggerLevels {
+		if len(name) > longest && (name == "" || logger == name || strings.HasPrefix(logger, name+".")) {
+			level, longest = l, len(name)
+		}
+	}
+	return level
+}
+
+// ParseLoggerLevels parses entries like "com.mycompany.*=DEBUG" into a map of logger or package names to levels.
+// Like in logback, the level of a package applies to all its loggers and sub-packages, a trailing ".*" is optional
+// and "*" sets the level of all loggers. The level OFF hides all logs of a logger.
+func ParseLoggerLevels(entries []string) (map[string]string, error) {
+	levels := map[string]string{}
+	for _, entry := range entries {
+		name, level, ok := strings.Cut(entry, "=")
+		name, level = strings.TrimSpace(name), strings.ToUpper(strings.TrimSpace(level))
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid logger level: %q (expected <logger>=<level>, e.g. \"com.foo.*=DEBUG\")", entry)
+		}
+		if _, known := LevelPriority[level]; !known || level == "-----" {
+			return nil, fmt.Errorf("invalid log level in %q (allowed: TRACE, DEBUG, INFO, WARN, ERROR, OFF)", entry)
+		}
+
+		name = strings.TrimSuffix(strings.TrimSuffix(name, "*"), ".")
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+// loggerPatterns caches the compiled logger patterns
+var loggerPatterns sync.Map
+
+// matchesLogger checks if the logger name matches any of the patterns. Supported patterns are:
+//   - exact names (e.g. "com.foo.Service")
+//   - package prefixes ending with "*", matching packages and sub-packages (e.g. "com.foo.core.*")
+//   - globs, where "*" matches one package segment and "**" any number of them (e.g. "com.**.orders.*Service")
+//   - regular expressions enclosed in slashes (e.g. "/Order(Service|Repository)$/")
+func matchesLogger(patterns []string, logger string) bool {
+	for _, pattern := range patterns {
+		re, err := compileLoggerPattern(pattern)
+		if err != nil {
+			// Invalid pattern → match nothing (validated upfront by the command)
+			continue
+		}
+		if re.MatchString(logger) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileLoggerPattern converts a logger pattern into a regular expression, compiling it on first use
+func compileLoggerPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := loggerPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	var expr string
+	switch {
+	case len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/"):
+		expr = pattern[1 : len(pattern)-1]
+	default:
+		// A trailing "*" matches the rest of the name, including sub-packages
+		prefix, trailing := strings.CutSuffix(pattern, "*")
+		if strings.HasSuffix(prefix, "*") {
+			prefix, trailing = pattern, false
+		}
+
+		var sb strings.Builder
+		for i, part := range strings.Split(prefix, "**") {
+			if i > 0 {
+				sb.WriteString(".*")
 			}
-		} else {
-			// Exact match
-			if logger == pattern {
-				return true
+			segments := strings.Split(part, "*")
+			for j, segment := range segments {
+				segments[j] = regexp.QuoteMeta(segment)
 			}
+			sb.WriteString(strings.Join(segments, `[^.]*`))
+		}
+		if trailing {
+			sb.WriteString(".*")
+		}
+		expr = "^" + sb.String() + "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	loggerPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// ValidateLoggerPatterns checks that the regular expressions among the logger patterns compile
+func ValidateLoggerPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := compileLoggerPattern(pattern); err != nil {
+			return fmt.Errorf("invalid logger pattern: %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchesSources checks if the source passes the source patterns: it must match one of the include patterns (if there
+// are any) and none of the exclude patterns starting with "!".
+func (f *Filter) matchesSources(source string) bool {
+	included, hasIncludes := false, false
+	for _, pattern := range f.Sources {
+		if exclude, ok := strings.CutPrefix(pattern, "!"); ok {
+			if matchesSource(exclude, source) {
+				return false
+			}
+			continue
+		}
+
+		hasIncludes = true
+		if matchesSource(pattern, source) {
+			included = true
+		}
+	}
+	return included || !hasIncludes
+}
+
+// matchesSource checks if the glob pattern matches the source or one of its leading segments, case-insensitive.
+// So "RTR" matches "RTR/0", "APP/PROC/WEB/*" matches all web instances and "APP/TASK" matches all tasks.
+func matchesSource(pattern string, source string) bool {
+	pattern, source = strings.ToUpper(pattern), strings.ToUpper(source)
+
+	for i := 0; i <= len(source); i++ {
+		if i < len(source) && source[i] != '/' {
+			continue
+		}
+		if ok, _ := path.Match(pattern, source[:i]); ok {
+			return true
 		}
 	}
 	return false
 }
+
+// ValidateSources checks the syntax of source patterns
+func ValidateSources(patterns []string) error {
+	for _, pattern := range patterns {
+		glob := strings.TrimPrefix(pattern, "!")
+		if _, err := path.Match(glob, ""); glob == "" || err != nil {
+			return fmt.Errorf("invalid source pattern: %q", pattern)
+		}
+	}
+	return nil
+}
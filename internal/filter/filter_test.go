@@ -92,6 +92,18 @@ func TestFilter_Matches_LoggerFilter(t *testing.T) {
 		{"Empty logger filter matches everything", []string{}, "anything.at.all", true},
 		{"Empty string filter", []string{""}, "com.foo.Service", true},
 		{"Wildcard only", []string{"*"}, "com.foo.Service", false},
+
+		// Globs
+		{"Glob single segment", []string{"com.*.auth.Service"}, "com.foo.auth.Service", false},
+		{"Glob single segment does not cross packages", []string{"com.*.auth.Service"}, "com.foo.bar.auth.Service", true},
+		{"Glob any segments", []string{"com.**.auth.Service"}, "com.foo.bar.auth.Service", false},
+		{"Glob within segment", []string{"com.foo.auth.*Service"}, "com.foo.auth.AuthService", false},
+		{"Glob trailing double star", []string{"com.foo.**"}, "com.foo.auth.Service", false},
+
+		// Regular expressions
+		{"Regex matches", []string{"/Auth(Service|Client)$/"}, "com.foo.auth.AuthClient", false},
+		{"Regex no match", []string{"/Auth(Service|Client)$/"}, "com.foo.auth.AuthFactory", true},
+		{"Invalid regex matches nothing", []string{"/(/"}, "com.foo.auth.Service", true},
 	}
 
 	for _, tt := range tests {
@@ -106,3 +118,156 @@ func TestFilter_Matches_LoggerFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestFilter_Matches_LoggerLevels(t *testing.T) {
+	loggerLevels := []string{"com.mycompany.*=DEBUG", "com.mycompany.noisy=ERROR", "org.springframework.*=WARN", "com.zaxxer.*=off"}
+
+	tests := []struct {
+		name          string
+		messageLevel  string
+		messageLogger string
+		expectMatch   bool
+	}{
+		{"Package override lowers level", "DEBUG", "com.mycompany.orders.Service", true},
+		{"Package override below override", "TRACE", "com.mycompany.orders.Service", false},
+		{"Longest prefix wins", "WARN", "com.mycompany.noisy.Poller", false},
+		{"Longest prefix wins match", "ERROR", "com.mycompany.noisy.Poller", true},
+		{"Package override raises level", "INFO", "org.springframework.web.Servlet", false},
+		{"Package override raises level match", "WARN", "org.springframework.web.Servlet", true},
+		{"Prefix must end at a package boundary", "DEBUG", "com.mycompanyother.Service", false},
+		{"Other loggers use default level", "INFO", "com.other.Service", true},
+		{"Other loggers use default level no match", "DEBUG", "com.other.Service", false},
+		{"OFF hides everything", "ERROR", "com.zaxxer.hikari.Pool", false},
+		{"OFF hides logs without level", "-----", "com.zaxxer.hikari.Pool", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(&config.Config{Level: "INFO", LoggerLevels: loggerLevels})
+			m := msg(tt.messageLevel, tt.messageLogger)
+
+			got := f.Matches(m)
+			if got != tt.expectMatch {
+				t.Errorf("Expected match = %v, got %v", tt.expectMatch, got)
+			}
+		})
+	}
+}
+
+func TestParseLoggerLevels(t *testing.T) {
+	levels, err := ParseLoggerLevels([]string{"com.foo.*=debug", " com.bar = WARN ", "*=ERROR", "com.baz.Service=OFF"})
+	if err != nil {
+		t.Fatalf("Expected entries to be valid, got: %v", err)
+	}
+
+	expected := map[string]string{"com.foo": "DEBUG", "com.bar": "WARN", "": "ERROR", "com.baz.Service": "OFF"}
+	if len(levels) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, levels)
+	}
+	for name, level := range expected {
+		if levels[name] != level {
+			t.Errorf("Expected level %s for %q, got %q", level, name, levels[name])
+		}
+	}
+
+	for _, entry := range []string{"com.foo", "=DEBUG", "com.foo=FOO", "com.foo=-----"} {
+		if _, err := ParseLoggerLevels([]string{entry}); err == nil {
+			t.Errorf("Expected %q to be invalid", entry)
+		}
+	}
+}
+
+func TestFilter_Matches_IncludeLogger(t *testing.T) {
+	tests := []struct {
+		name          string
+		include       []string
+		exclude       []string
+		messageLogger string
+		expectMatch   bool
+	}{
+		{"Exact include", []string{"com.foo.auth.Service"}, nil, "com.foo.auth.Service", true},
+		{"Exact include other logger", []string{"com.foo.auth.Service"}, nil, "com.foo.auth.Client", false},
+		{"Package include", []string{"com.foo.orders.*"}, nil, "com.foo.orders.api.Controller", true},
+		{"Package include other package", []string{"com.foo.orders.*"}, nil, "com.foo.auth.Service", false},
+		{"Multiple includes", []string{"com.foo.orders.*", "com.foo.auth.*"}, nil, "com.foo.auth.Service", true},
+		{"Glob include", []string{"com.**.orders.*"}, nil, "com.foo.bar.orders.Service", true},
+		{"Regex include", []string{"/^com\\.foo\\./"}, nil, "com.foo.auth.Service", true},
+		{"Include hides logs without logger", []string{"com.foo.*"}, nil, "", false},
+
+		// Excludes take precedence over includes
+		{"Excluded sub-package of included package", []string{"com.foo.*"}, []string{"com.foo.noisy.*"}, "com.foo.noisy.Poller", false},
+		{"Included package without exclusion", []string{"com.foo.*"}, []string{"com.foo.noisy.*"}, "com.foo.orders.Service", true},
+		{"Same pattern included and excluded", []string{"com.foo.*"}, []string{"com.foo.*"}, "com.foo.orders.Service", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(&config.Config{Level: "DEBUG", Include: tt.include, Exclude: tt.exclude})
+			m := msg("INFO", tt.messageLogger)
+
+			got := f.Matches(m)
+			if got != tt.expectMatch {
+				t.Errorf("Expected match = %v, got %v", tt.expectMatch, got)
+			}
+		})
+	}
+}
+
+func TestValidateLoggerPatterns(t *testing.T) {
+	if err := ValidateLoggerPatterns([]string{"com.foo.*", "com.**.Service", "/Service$/", "*"}); err != nil {
+		t.Errorf("Expected patterns to be valid, got: %v", err)
+	}
+
+	if err := ValidateLoggerPatterns([]string{"/(/"}); err == nil {
+		t.Error("Expected invalid regular expression to be rejected")
+	}
+}
+
+func TestFilter_Matches_Sources(t *testing.T) {
+	tests := []struct {
+		name        string
+		sources     []string
+		source      string
+		expectMatch bool
+	}{
+		{"No patterns include everything", nil, "CELL/0", true},
+		{"Source type", []string{"RTR"}, "RTR/6", true},
+		{"Source type lowercase", []string{"rtr"}, "RTR/6", true},
+		{"Other source type", []string{"RTR"}, "APP/PROC/WEB/0", false},
+		{"Glob", []string{"APP/PROC/WEB/*"}, "APP/PROC/WEB/2", true},
+		{"Glob other process type", []string{"APP/PROC/WEB/*"}, "APP/PROC/WORKER/0", false},
+		{"Leading segments", []string{"APP/TASK"}, "APP/TASK/migrate/0", true},
+		{"Partial segment", []string{"AP"}, "APP/PROC/WEB/0", false},
+		{"One of several", []string{"RTR", "STG"}, "STG/0", true},
+		{"Exclude", []string{"!CELL"}, "CELL/0", false},
+		{"Exclude other", []string{"!CELL"}, "APP/PROC/WEB/0", true},
+		{"Exclude wins", []string{"APP", "!APP/PROC/WEB/1"}, "APP/PROC/WEB/1", false},
+		{"Include with exclude", []string{"APP", "!APP/PROC/WEB/1"}, "APP/PROC/WEB/0", true},
+		{"Message without source", []string{"APP"}, "", false},
+		{"Message without source and only excludes", []string{"!CELL"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(&config.Config{Level: "TRACE", Sources: tt.sources})
+			m := &parser.LogMessage{Level: "INFO", Source: tt.source}
+
+			got := f.Matches(m)
+			if got != tt.expectMatch {
+				t.Errorf("Expected match = %v, got %v", tt.expectMatch, got)
+			}
+		})
+	}
+}
+
+func TestValidateSources(t *testing.T) {
+	if err := ValidateSources([]string{"RTR", "!CELL", "APP/PROC/*/[0-2]"}); err != nil {
+		t.Errorf("Expected patterns to be valid, got: %v", err)
+	}
+
+	for _, pattern := range []string{"", "!", "APP/[", "!["} {
+		if err := ValidateSources([]string{pattern}); err == nil {
+			t.Errorf("Expected %q to be invalid", pattern)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// grepPatterns caches the compiled --grep patterns
+var grepPatterns sync.Map
+
+// CompileGrep converts a --grep pattern into a regular expression. Patterns enclosed in slashes are regular
+// expressions (e.g. "/time(out|d out)/"), all others are searched as substring.
+func CompileGrep(pattern string) (*regexp.Regexp, error) {
+	if re, ok := grepPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		expr = pattern[1 : len(pattern)-1]
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grep pattern: %q: %w", pattern, err)
+	}
+	if pattern == "" || re.MatchString("") {
+		return nil, fmt.Errorf("invalid grep pattern: %q (matches empty text)", pattern)
+	}
+
+	grepPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// CompileGreps compiles a list of --grep patterns
+func CompileGreps(patterns []string) ([]*regexp.Regexp, error) {
+	result := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := CompileGrep(pattern)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, re)
+	}
+	return result, nil
+}
+
+// matchesGrep checks if any of the expressions matches the message, logger or stack trace of msg
+func matchesGrep(expressions []*regexp.Regexp, msg *parser.LogMessage) bool {
+	for _, re := range expressions {
+		if re.MatchString(msg.Message) || re.MatchString(msg.Logger) {
+			return true
+		}
+		for _, line := range msg.StackTrace {
+			if re.MatchString(line) {
+				return true
+			}
+		}
+	}
+	return false
+}
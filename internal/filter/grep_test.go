@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+func TestFilter_Matches_Grep(t *testing.T) {
+	m := &parser.LogMessage{
+		Level:      "ERROR",
+		Logger:     "com.foo.orders.OrderService",
+		Message:    "Request timed out after 30s",
+		StackTrace: []string{"java.net.SocketTimeoutException: Read timed out", "\tat com.foo.Client.call(Client.java:42)"},
+	}
+
+	tests := []struct {
+		name        string
+		grep        []string
+		grepV       []string
+		expectMatch bool
+	}{
+		{"Substring in message", []string{"timed out"}, nil, true},
+		{"Substring is case-sensitive", []string{"Timed Out"}, nil, false},
+		{"Substring with regex characters", []string{"30s"}, nil, true},
+		{"Substring in logger", []string{"OrderService"}, nil, true},
+		{"Substring in stack trace", []string{"SocketTimeoutException"}, nil, true},
+		{"No match", []string{"connection refused"}, nil, false},
+		{"Regex", []string{"/after [0-9]+s$/"}, nil, true},
+		{"Case-insensitive regex", []string{"/(?i)REQUEST/"}, nil, true},
+		{"One of several", []string{"refused", "timed out"}, nil, true},
+		{"Dot is no wildcard in substrings", []string{"Client.java.42"}, nil, false},
+		{"Grep-v excludes", nil, []string{"timed out"}, false},
+		{"Grep-v other text", nil, []string{"refused"}, true},
+		{"Grep-v in stack trace", nil, []string{"/Client\\.java/"}, false},
+		{"Grep-v wins", []string{"timed out"}, []string{"SocketTimeout"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(&config.Config{Level: "TRACE", Grep: tt.grep, GrepV: tt.grepV})
+
+			got := f.Matches(m)
+			if got != tt.expectMatch {
+				t.Errorf("Expected match = %v, got %v", tt.expectMatch, got)
+			}
+		})
+	}
+}
+
+func TestCompileGrep(t *testing.T) {
+	for _, pattern := range []string{"timeout", "/time(out|d out)/", "/", "a.b"} {
+		if _, err := CompileGrep(pattern); err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", pattern, err)
+		}
+	}
+
+	for _, pattern := range []string{"", "//", "/(/", "/a*/"} {
+		if _, err := CompileGrep(pattern); err == nil {
+			t.Errorf("Expected %q to be invalid", pattern)
+		}
+	}
+}
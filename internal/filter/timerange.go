@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// absoluteLayouts are the accepted layouts of absolute times. Times without offset are local times.
+var absoluteLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700", // as reported by cf logs
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// clockLayouts are the accepted layouts of times of day, which refer to the day of the first log
+var clockLayouts = []string{
+	"15:04:05",
+	"15:04",
+}
+
+// TimeBound is the start or end of a time range
+type TimeBound struct {
+	Time      time.Time     // absolute time, if not relative to the first log
+	FromFirst bool          // Offset is relative to the time of the first log
+	Clock     bool          // Offset is a time of day on the day of the first log
+	Offset    time.Duration // offset to the first log or its midnight
+}
+
+// ParseTimeBound parses an absolute time (e.g. "2024-05-01T14:30:00Z", "2024-05-01 14:30"), a time of day on the
+// day of the first log (e.g. "14:30"), a duration before now (e.g. "15m") or a duration after the first log
+// (e.g. "+10m")
+func ParseTimeBound(value string, now time.Time) (*TimeBound, error) {
+	value = strings.TrimSpace(value)
+
+	if offset, ok := strings.CutPrefix(value, "+"); ok {
+		d, err := time.ParseDuration(offset)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid time: %s (expected a duration after the first log, e.g. +10m)", value)
+		}
+		return &TimeBound{FromFirst: true, Offset: d}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return nil, fmt.Errorf("invalid time: %s (durations before now must not be negative)", value)
+		}
+		return &TimeBound{Time: now.Add(-d)}, nil
+	}
+
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &TimeBound{Time: t}, nil
+		}
+	}
+
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+			return &TimeBound{Clock: true, Offset: offset}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid time: %s (expected e.g. 2024-05-01T14:30:00Z, \"2024-05-01 14:30\", 14:30, 15m or +10m)", value)
+}
+
+// resolve returns the time of the bound, given the time of the first log
+func (b *TimeBound) resolve(first time.Time) time.Time {
+	switch {
+	case b.FromFirst:
+		return first.Add(b.Offset)
+	case b.Clock:
+		local := first.Local()
+		return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local).Add(b.Offset)
+	default:
+		return b.Time
+	}
+}
+
+// ValidateTimeRange checks the --since and --until values
+func ValidateTimeRange(since string, until string, now time.Time) error {
+	var bounds [2]*TimeBound
+	for i, value := range []string{since, until} {
+		if value == "" {
+			continue
+		}
+		bound, err := ParseTimeBound(value, now)
+		if err != nil {
+			return err
+		}
+		bounds[i] = bound
+	}
+
+	// Bounds of different kinds depend on the first log and can't be compared upfront
+	if s, u := bounds[0], bounds[1]; s != nil && u != nil && s.FromFirst == u.FromFirst && s.Clock == u.Clock &&
+		s.resolve(now).After(u.resolve(now)) {
+		return fmt.Errorf("invalid time range: --since %s is after --until %s", since, until)
+	}
+	return nil
+}
+
+// inTimeRange reports whether the time of msg is within Since and Until. Logs without a timestamp are excluded.
+func (f *Filter) inTimeRange(msg *parser.LogMessage) bool {
+	if msg.Time.IsZero() {
+		return false
+	}
+	since, until := f.timeRange(msg.Time)
+	if f.Since != nil && msg.Time.Before(since) {
+		return false
+	}
+	if f.Until != nil && msg.Time.After(until) {
+		return false
+	}
+	return true
+}
+
+// PastUntil reports whether msg was logged after the end of the time range
+func (f *Filter) PastUntil(msg *parser.LogMessage) bool {
+	if f.Until == nil || msg.Time.IsZero() {
+		return false
+	}
+	_, until := f.timeRange(msg.Time)
+	return msg.Time.After(until)
+}
+
+// timeRange returns the resolved Since and Until. The first log seen is the one the relative bounds refer to.
+func (f *Filter) timeRange(t time.Time) (time.Time, time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.first.IsZero() {
+		f.first = t
+	}
+
+	var since, until time.Time
+	if f.Since != nil {
+		since = f.Since.resolve(f.first)
+	}
+	if f.Until != nil {
+		until = f.Until.resolve(f.first)
+	}
+	return since, until
+}
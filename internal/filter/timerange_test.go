@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package filter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected TimeBound
+	}{
+		{"2024-05-01T14:30:00Z", TimeBound{Time: time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)}},
+		{"2024-05-01T16:30:00.25+02:00", TimeBound{Time: time.Date(2024, 5, 1, 14, 30, 0, 250000000, time.UTC)}},
+		{"2024-05-01T16:30:00.71+0200", TimeBound{Time: time.Date(2024, 5, 1, 14, 30, 0, 710000000, time.UTC)}},
+		{"2024-05-01 14:30", TimeBound{Time: time.Date(2024, 5, 1, 14, 30, 0, 0, time.Local)}},
+		{"2024-05-01", TimeBound{Time: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)}},
+		{"15m", TimeBound{Time: time.Date(2024, 5, 1, 14, 45, 0, 0, time.UTC)}},
+		{"+10m", TimeBound{FromFirst: true, Offset: 10 * time.Minute}},
+		{"14:30", TimeBound{Clock: true, Offset: 14*time.Hour + 30*time.Minute}},
+		{"14:30:15.5", TimeBound{Clock: true, Offset: 14*time.Hour + 30*time.Minute + 15500*time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTimeBound(tt.value, now)
+		if err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", tt.value, err)
+			continue
+		}
+		if !got.Time.Equal(tt.expected.Time) || got.FromFirst != tt.expected.FromFirst || got.Clock != tt.expected.Clock || got.Offset != tt.expected.Offset {
+			t.Errorf("%q: expected %+v, got %+v", tt.value, tt.expected, *got)
+		}
+	}
+
+	for _, value := range []string{"", "soon", "-5m", "+-5m", "25:00", "2024-13-01"} {
+		if _, err := ParseTimeBound(value, now); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+}
+
+func TestFilter_Matches_TimeRange(t *testing.T) {
+	first := time.Date(2024, 5, 1, 14, 0, 0, 0, time.Local)
+	at := func(minutes int) *parser.LogMessage {
+		return &parser.LogMessage{Level: "INFO", Time: first.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	tests := []struct {
+		name     string
+		since    string
+		until    string
+		expected []int // minutes after the first log of the matching logs
+	}{
+		{"Absolute range, inclusive", "2024-05-01 14:05", "2024-05-01 14:10", []int{5, 10}},
+		{"Relative to the first log", "+5m", "+15m", []int{5, 10, 15}},
+		{"Time of day", "14:12", "", []int{15, 20}},
+		{"Only until", "", "14:05", []int{0, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(&config.Config{Level: "TRACE", Since: tt.since, Until: tt.until})
+
+			var got []int
+			for _, minutes := range []int{0, 5, 10, 15, 20} {
+				if f.Matches(at(minutes)) {
+					got = append(got, minutes)
+				}
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	f := New(&config.Config{Level: "TRACE", Since: "+0s"})
+	if f.Matches(&parser.LogMessage{Level: "INFO"}) {
+		t.Error("Expected logs without timestamp to be excluded")
+	}
+}
+
+func TestFilter_PastUntil(t *testing.T) {
+	first := time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)
+	f := New(&config.Config{Level: "TRACE", Until: "+1m"})
+
+	if f.PastUntil(&parser.LogMessage{Time: first}) || f.PastUntil(&parser.LogMessage{Time: first.Add(time.Minute)}) {
+		t.Error("Expected logs within the range not to be past until")
+	}
+	if !f.PastUntil(&parser.LogMessage{Time: first.Add(61 * time.Second)}) {
+		t.Error("Expected log after the range to be past until")
+	}
+	if f.PastUntil(&parser.LogMessage{}) {
+		t.Error("Expected log without timestamp not to be past until")
+	}
+}
+
+func TestValidateTimeRange(t *testing.T) {
+	now := time.Now()
+
+	for _, r := range [][2]string{{"", ""}, {"14:00", "14:30"}, {"+10m", "14:00"}, {"30m", "10m"}, {"2024-05-01", "+5m"}} {
+		if err := ValidateTimeRange(r[0], r[1], now); err != nil {
+			t.Errorf("Expected --since %q --until %q to be valid, got: %v", r[0], r[1], err)
+		}
+	}
+
+	err := ValidateTimeRange("+10m", "+5m", now)
+	if err == nil || !strings.Contains(err.Error(), "invalid time range: --since +10m is after --until +5m") {
+		t.Errorf("Expected invalid time range, got: %v", err)
+	}
+}
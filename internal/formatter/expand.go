@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Colors of the expanded payloads
+var (
+	keyColor     = NewColor(color.FgBlue).SprintFunc()
+	stringColor  = NewColor(color.FgGreen).SprintFunc()
+	numberColor  = NewColor(color.FgMagenta).SprintFunc()
+	literalColor = NewColor(color.FgYellow).SprintFunc() // true, false and null
+	collapsed    = NewColor(color.Faint).SprintfFunc()
+)
+
+// jsonValue is a decoded JSON value that keeps the order of object keys
+type jsonValue struct {
+	kind   byte // '{', '[' or 0 for scalars
+	scalar any  // json.Number, string, bool or nil
+	keys   []string
+	values []*jsonValue // values of the keys or items of the array
+}
+
+// expandMessage looks for JSON or logfmt payloads in message. It returns the message with JSON payloads replaced
+// by a placeholder or without the logfmt pairs, and the payloads pretty-printed line by line. Arrays nested deeper
+// than depth are collapsed. If there is no payload, message is returned unchanged.
+func expandMessage(message string, depth int) (string, []string) {
+	if headline, lines := expandJSON(message, depth); len(lines) > 0 {
+		return headline, lines
+	}
+	return expandLogfmt(message)
+}
+
+// maxDecodeAttempts limits the JSON payloads looked for in one message, so messages full of brackets stay fast
+const maxDecodeAttempts = 100
+
+// expandJSON replaces the JSON objects and arrays within message by placeholders and renders them
+func expandJSON(message string, depth int) (string, []string) {
+	var headline strings.Builder
+	var lines []string
+
+	pos, attempts := 0, 0
+	for i := 0; i < len(message) && attempts < maxDecodeAttempts; i++ {
+		if message[i] != '{' && message[i] != '[' {
+			continue
+		}
+
+		attempts++
+		dec := json.NewDecoder(strings.NewReader(message[i:]))
+		dec.UseNumber()
+		value, err := decodeJSON(dec)
+		if err != nil {
+			// The tokens read so far can't start another payload, otherwise the decoding would have succeeded
+			i += max(int(dec.InputOffset())-1, 0)
+			continue
+		}
+		if !value.expandable() {
+			continue
+		}
+
+		headline.WriteString(message[pos:i])
+		headline.WriteString(collapsed("%c…%c", value.kind, closing(value.kind)))
+		lines = append(lines, value.render(1, depth)...)
+
+		i += int(dec.InputOffset()) - 1
+		pos = i + 1
+	}
+	if len(lines) == 0 {
+		return message, nil
+	}
+
+	headline.WriteString(message[pos:])
+	return headline.String(), lines
+}
+
+// decodeJSON decodes the next value of dec, keeping the order of object keys
+func decodeJSON(dec *json.Decoder) (*jsonValue, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return &jsonValue{scalar: token}, nil
+	}
+
+	value := &jsonValue{kind: byte(delim)}
+	for dec.More() {
+		if value.kind == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value.keys = append(value.keys, key.(string))
+		}
+		item, err := decodeJSON(dec)
+		if err != nil {
+			return nil, err
+		}
+		value.values = append(value.values, item)
+	}
+
+	// Closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// expandable reports whether the value is worth to be expanded: a non-empty object or an array containing one.
+// This avoids expanding text like "[1]" or "{}".
+func (v *jsonValue) expandable() bool {
+	switch v.kind {
+	case '{':
+		return len(v.keys) > 0
+	case '[':
+		for _, item := range v.values {
+			if item.kind == '{' && item.expandable() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// render returns the lines of the value at the given nesting level, indented by two spaces per level
+func (v *jsonValue) render(level int, depth int) []string {
+	if v.kind == 0 {
+		return []string{renderScalar(v.scalar)}
+	}
+	if len(v.values) == 0 {
+		return []string{string(v.kind) + string(closing(v.kind))}
+	}
+	if v.kind == '[' && level > depth {
+		return []string{collapsed("[… %d items]", len(v.values))}
+	}
+
+	lines := []string{string(v.kind)}
+	for i, item := range v.values {
+		itemLines := item.render(level+1, depth)
+		if v.kind == '{' {
+			itemLines[0] = keyColor(strconv.Quote(v.keys[i])) + ": " + itemLines[0]
+		}
+		for j := range itemLines {
+			itemLines[j] = "  " + itemLines[j]
+		}
+		if i < len(v.values)-1 {
+			itemLines[len(itemLines)-1] += ","
+		}
+		lines = append(lines, itemLines...)
+	}
+	return append(lines, string(closing(v.kind)))
+}
+
+func renderScalar(value any) string {
+	switch value := value.(type) {
+	case string:
+		return stringColor(strconv.Quote(value))
+	case json.Number:
+		return numberColor(value.String())
+	case nil:
+		return literalColor("null")
+	default:
+		return literalColor(fmt.Sprint(value))
+	}
+}
+
+func closing(kind byte) byte {
+	if kind == '{' {
+		return '}'
+	}
+	return ']'
+}
+
+// logfmtPairRegex matches a key=value pair, the value optionally quoted
+var logfmtPairRegex = regexp.MustCompile(`^([A-Za-z_][\w.\-]*)=("(?:[^"\\]|\\.)*"|\S*)(?:\s+|$)`)
+
+// numberRegex matches numeric logfmt values, which are colored like JSON numbers
+var numberRegex = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][+-]?\d+)?$`)
+
+// expandLogfmt moves trailing key=value pairs of message below it, if there are at least two of them
+func expandLogfmt(message string) (string, []string) {
+	trimmed := strings.TrimRight(message, " ")
+
+	// The pairs start at the first word from which on the rest of the message consists of pairs only. Scanning from
+	// the end, pairs[i] counts the pairs the rest of the message consists of from word i on, so each pair is parsed
+	// once.
+	pairs := map[int]int{len(trimmed): 0}
+	start := -1
+	for i := len(trimmed) - 1; i >= 0; i-- {
+		if trimmed[i] == ' ' || (i > 0 && trimmed[i-1] != ' ') {
+			continue
+		}
+		m := logfmtPairRegex.FindStringIndex(trimmed[i:])
+		if m == nil {
+			continue
+		}
+		following, ok := pairs[i+m[1]]
+		if !ok {
+			continue
+		}
+		pairs[i] = following + 1
+		if pairs[i] >= 2 {
+			start = i
+		}
+	}
+	if start < 0 {
+		return message, nil
+	}
+
+	keys, values, _ := parseLogfmt(trimmed[start:])
+	return strings.TrimRight(trimmed[:start], " "), renderLogfmt(keys, values)
+}
+
+// parseLogfmt parses s as a sequence of key=value pairs
+func parseLogfmt(s string) ([]string, []string, bool) {
+	var keys, values []string
+	for s != "" {
+		m := logfmtPairRegex.FindStringSubmatch(s)
+		if m == nil {
+			return nil, nil, false
+		}
+		value := m[2]
+		if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+			value = unquoted
+		}
+		keys, values = append(keys, m[1]), append(values, value)
+		s = s[len(m[0]):]
+	}
+	return keys, values, true
+}
+
+// renderLogfmt renders one pair per line, with the values aligned
+func renderLogfmt(keys []string, values []string) []string {
+	width := 0
+	for _, key := range keys {
+		width = max(width, len(key))
+	}
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		value := values[i]
+		switch {
+		case numberRegex.MatchString(value):
+			value = numberColor(value)
+		case value == "true" || value == "false" || value == "null":
+			value = literalColor(value)
+		default:
+			value = stringColor(value)
+		}
+		lines[i] = keyColor(key) + strings.Repeat(" ", width-len(key)) + " = " + value
+	}
+	return lines
+}
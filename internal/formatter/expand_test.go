@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+func TestExpandMessage(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = origNoColor }()
+
+	tests := []struct {
+		name             string
+		message          string
+		depth            int
+		expectedHeadline string
+		expectedLines    []string
+	}{
+		{
+			name:             "Embedded JSON object keeps key order",
+			message:          `Response: {"id":42,"status":"PAID","amount":12.5,"ok":true,"note":null} took 31ms`,
+			depth:            3,
+			expectedHeadline: "Response: {…} took 31ms",
+			expectedLines:    []string{"{", `  "id": 42,`, `  "status": "PAID",`, `  "amount": 12.5,`, `  "ok": true,`, `  "note": null`, "}"},
+		},
+		{
+			name:             "Nested containers",
+			message:          `{"order":{"lines":[{"sku":"A-1"}],"tags":[]}}`,
+			depth:            3,
+			expectedHeadline: "{…}",
+			expectedLines:    []string{"{", `  "order": {`, `    "lines": [`, "      {", `        "sku": "A-1"`, "      }", "    ],", `    "tags": []`, "  }", "}"},
+		},
+		{
+			name:             "Arrays beyond the depth are collapsed",
+			message:          `{"lines":[{"tags":["a","b","c"]}]}`,
+			depth:            2,
+			expectedHeadline: "{…}",
+			expectedLines:    []string{"{", `  "lines": [`, "    {", `      "tags": [… 3 items]`, "    }", "  ]", "}"},
+		},
+		{
+			name:             "Several JSON payloads",
+			message:          `old={"a":1} new={"a":2}`,
+			depth:            3,
+			expectedHeadline: "old={…} new={…}",
+			expectedLines:    []string{"{", `  "a": 1`, "}", "{", `  "a": 2`, "}"},
+		},
+		{
+			name:             "Trailing logfmt pairs",
+			message:          `Request completed method=GET path=/orders status=200 user="jane doe"`,
+			depth:            3,
+			expectedHeadline: "Request completed",
+			expectedLines:    []string{"method = GET", "path   = /orders", "status = 200", "user   = jane doe"},
+		},
+		{
+			name:             "Single pair is no payload",
+			message:          "Retrying attempt=2",
+			depth:            3,
+			expectedHeadline: "Retrying attempt=2",
+		},
+		{
+			name:             "Brackets in text are no payload",
+			message:          "Started [main] in 3 s, retry [1] {} [[2]]x",
+			depth:            3,
+			expectedHeadline: "Started [main] in 3 s, retry [1] {} [[2]]x",
+		},
+		{
+			name:             "Array of objects",
+			message:          `Users: [{"id":1},{"id":2}]`,
+			depth:            3,
+			expectedHeadline: "Users: […]",
+			expectedLines:    []string{"[", "  {", `    "id": 1`, "  },", "  {", `    "id": 2`, "  }", "]"},
+		},
+		{
+			name:             "Invalid JSON is no payload",
+			message:          `Parse error at {"a": 1,`,
+			depth:            3,
+			expectedHeadline: `Parse error at {"a": 1,`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headline, lines := expandMessage(tt.message, tt.depth)
+
+			if headline != tt.expectedHeadline {
+				t.Errorf("Expected headline %q, got %q", tt.expectedHeadline, headline)
+			}
+			if strings.Join(lines, "\n") != strings.Join(tt.expectedLines, "\n") {
+				t.Errorf("Expected lines:\n%s\ngot:\n%s", strings.Join(tt.expectedLines, "\n"), strings.Join(lines, "\n"))
+			}
+		})
+	}
+}
+
+func TestExpandMessage_Pathological(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = origNoColor }()
+
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{"Unclosed brackets", strings.Repeat("[", 100000)},
+		{"Unclosed objects", strings.Repeat(`{"a":`, 20000)},
+		{"Brackets in text", strings.Repeat("[x] {y} ", 20000)},
+		{"Pairs followed by text", strings.Repeat("k=v ", 20000) + "done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			headline, lines := expandMessage(tt.message, 3)
+
+			if headline != tt.message || len(lines) > 0 {
+				t.Errorf("Expected no payload, got %d lines", len(lines))
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("Expected expansion to take linear time, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestExpandMessage_Colors(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	_, lines := expandMessage(`{"id":42}`, 3)
+	if len(lines) != 3 || lines[1] != "  \x1b[34m\"id\"\x1b[0m: \x1b[35m42\x1b[0m" {
+		t.Errorf("Expected colored key and number, got %q", lines)
+	}
+}
+
+func TestFormat_ExpandJSON(t *testing.T) {
+	msg := &parser.LogMessage{
+		Timestamp:  "2024-05-01T14:00:00.00",
+		Level:      "INFO",
+		Logger:     "com.acme.Client",
+		Message:    `Response {"id":42}`,
+		StackTrace: []string{"at com.acme.Client.call(Client.java:42)"},
+	}
+
+	output := Format(msg, NoColor(), &config.Config{ExpandJSON: true, ExpandDepth: 3})
+	expected := "2024-05-01T14:00:00.00 [INFO ] com.acme.Client                          : Response {…}\n" +
+		"    {\n" +
+		"      \"id\": 42\n" +
+		"    }\n" +
+		"    at com.acme.Client.call(Client.java:42)"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+
+	output = Format(msg, NoColor(), &config.Config{})
+	if strings.Contains(output, "\n    {") {
+		t.Errorf("Expected no expansion without --expand-json, got:\n%s", output)
+	}
+}
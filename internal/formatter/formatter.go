@@ -7,30 +7,94 @@ package formatter
 
 import (
 	"fmt"
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/filter"
 	"github.com/saschakiefer/cf-log-pretty/internal/parser"
 	"github.com/saschakiefer/cf-log-pretty/internal/util"
 )
 
+// Named time formats of the --time-format flag. Any other value is used as Go time layout.
+var timeLayouts = map[string]string{
+	"local":     "2006-01-02 15:04:05.000",
+	"utc":       "2006-01-02 15:04:05.000Z",
+	"rfc3339":   "2006-01-02T15:04:05.000Z07:00",
+	"time-only": "15:04:05.000",
+}
+
 // ColorFunc defines a flexible formatting function (with or without ANSI colors)
 type ColorFunc func(format string, a ...interface{}) string
 
 // Format renders a log message using the provided level color function
 func Format(msg *parser.LogMessage, colorizeLevel ColorFunc, cfg *config.Config) string {
+	return FormatAfter(msg, colorizeLevel, cfg, time.Time{})
+}
+
+// FormatAfter renders a log message like Format. Relative timestamps are relative to previous, the time of the
+// previously shown message (zero for the first one).
+func FormatAfter(msg *parser.LogMessage, colorizeLevel ColorFunc, cfg *config.Config, previous time.Time) string {
 	// Process log level with color
 	levelText := colorizeLevel("[%-5s]", msg.Level)
 
 	// Process message text
 	message := msg.Message
+	if message == "" && msg.Type == "request" {
+		message = requestSummary(msg)
+	}
+	if msg.Type == "router" {
+		message = accessLogSummary(msg, cfg.TruncateRaw)
+	}
+
+	// Move embedded JSON and logfmt payloads below the message
+	var expanded []string
+	if cfg.ExpandJSON && msg.Type != "router" {
+		message, expanded = expandMessage(message, cfg.ExpandDepth)
+	}
+
 	if msg.HasParseError && cfg.TruncateRaw {
 		message = truncToTerminal(message, 74)
 	}
 
-	// Process logger name
+	stackTrace := msg.StackTrace
+
+	// Color IDs, URLs, numbers and other tokens
+	if cfg.Highlight {
+		message = highlightTokens(message)
+		stackTrace = make([]string, len(msg.StackTrace))
+		for i, line := range msg.StackTrace {
+			stackTrace[i] = highlightTokens(line)
+		}
+	}
+
+	if msg.Repeated > 1 {
+		message += " " + repeatColor("(×%d in %s)", msg.Repeated, formatRepeatedFor(msg.RepeatedFor))
+	}
+
+	// Highlight the matches of --grep
+	if len(cfg.Grep) > 0 {
+		greps, _ := filter.CompileGreps(cfg.Grep)
+		message = highlightMatches(message, greps)
+		stackTrace = slices.Clone(stackTrace)
+		for i, line := range stackTrace {
+			stackTrace[i] = highlightMatches(line, greps)
+		}
+		for i, line := range expanded {
+			expanded[i] = highlightMatches(line, greps)
+		}
+	}
+
+	// Process logger name (router logs show the requested host instead)
 	logger := msg.Logger
+	if msg.Type == "router" {
+		logger = msg.Host
+	}
 	if cfg.RemovePrefix != "" {
 		logger = strings.Replace(logger, cfg.RemovePrefix, "", 1)
 	}
@@ -40,37 +104,200 @@ func Format(msg *parser.LogMessage, colorizeLevel ColorFunc, cfg *config.Config)
 		logger = parts[len(parts)-1]
 	}
 
-	logger = shortenMiddle(logger, 40)
-
 	// Build final output
-	result := fmt.Sprintf("%-22s %s %-40s : %s",
-		msg.Timestamp,
-		levelText,
-		logger,
-		message,
-	)
-
-	if len(msg.StackTrace) > 0 {
-		for _, line := range msg.StackTrace {
-			result += "\n    " + line
+	var result string
+	if cfg.Template != "" {
+		result = renderTemplate(cfg.Template, templateData{
+			LogMessage: msg,
+			Timestamp:  strings.TrimSpace(formatTimestamp(msg, cfg.TimeFormat, previous)),
+			Logger:     logger,
+			Message:    message,
+		})
+	} else {
+		if cfg.ShowSource {
+			levelText += " " + SourceColorizer(msg.Source)("%-8s", msg.ShortSource())
+		}
+		result = fmt.Sprintf("%s %s %s : %s",
+			styledPadded(theme.timestamp, formatTimestamp(msg, cfg.TimeFormat, previous)),
+			levelText,
+			styledPadded(theme.logger, shortenMiddle(logger, 40)),
+			message,
+		)
+	}
+
+	if msg.Label != "" {
+		result = LabelColorizer(msg.Label)("%s", msg.Label) + " " + result
+	}
+
+	for _, line := range expanded {
+		result += "\n    " + line
+	}
+
+	if len(stackTrace) > 0 {
+		for _, line := range stackTrace {
+			result += "\n    " + mapUncolored(line, func(s string) string { return styled(theme.stackTrace, s) })
 		}
 	}
 
 	return result
 }
 
-func shortenMiddle(input string, max int) string {
-	if len(input) <= max {
-		// Pad with spaces if shorter than max
-		return fmt.Sprintf("%-*s", max, input)
+// ValidateTimeFormat checks that format is one of the named time formats or a Go time layout
+func ValidateTimeFormat(format string) error {
+	switch format {
+	case "", "cf", "relative":
+		return nil
+	}
+	if _, ok := timeLayouts[format]; ok {
+		return nil
+	}
+
+	// A layout without any time element formats to itself
+	sample := time.Date(2001, time.November, 23, 22, 33, 44, 0, time.UTC)
+	if sample.Format(format) == format {
+		return fmt.Errorf("invalid time format: %s (allowed: cf, local, utc, rfc3339, time-only, relative or a Go time layout like \"15:04:05.000\")", format)
+	}
+	return nil
+}
+
+// formatTimestamp renders the time of msg in the given format, relative times relative to previous. Messages without
+// known time fall back to the CF timestamp.
+func formatTimestamp(msg *parser.LogMessage, format string, previous time.Time) string {
+	if format == "" || format == "cf" || msg.Time.IsZero() {
+		return fmt.Sprintf("%-22s", msg.Timestamp)
+	}
+
+	switch format {
+	case "relative":
+		elapsed := time.Duration(0)
+		if !previous.IsZero() {
+			elapsed = msg.Time.Sub(previous)
+		}
+
+		sign := "+"
+		if elapsed < 0 {
+			sign = "-"
+			elapsed = -elapsed
+		}
+		return fmt.Sprintf("%10s", fmt.Sprintf("%s%.3fs", sign, elapsed.Seconds()))
+	case "utc":
+		return msg.Time.UTC().Format(timeLayouts[format])
+	}
+
+	layout, ok := timeLayouts[format]
+	if !ok {
+		layout = format
+	}
+	return msg.Time.Local().Format(layout)
+}
+
+// requestSummary describes a request log of cf-java-logging-support, which carries no "msg" field
+func requestSummary(msg *parser.LogMessage) string {
+	status := StatusColorizer(msg.ResponseStatus)("%d", msg.ResponseStatus)
+	return fmt.Sprintf("%s %s -> %s (%.0fms)", msg.Method, msg.Request, status, msg.ResponseTimeMs)
+}
+
+// accessLogSummary renders a Gorouter access log as compact one-liner, e.g. `GET /orders -> 200 512B 31ms app#1 "curl/8.4.0"`.
+// With truncate it is shortened to the terminal width like raw messages.
+func accessLogSummary(msg *parser.LogMessage, truncate bool) string {
+	head := fmt.Sprintf("%s %s -> ", msg.Method, msg.Request)
+	status := fmt.Sprintf("%d", msg.ResponseStatus)
+	tail := fmt.Sprintf(" %dB %.0fms", msg.ResponseSizeB, msg.ResponseTimeMs)
+
+	if msg.AppIndex != "" {
+		tail += " app#" + msg.AppIndex
+	}
+	if msg.UserAgent != "" && msg.UserAgent != "-" {
+		tail += fmt.Sprintf(" %q", msg.UserAgent)
+	}
+
+	// Truncate the plain text, so the color codes of the status are not cut
+	if truncate {
+		plain := truncToTerminal(head+status+tail, 74)
+		if !strings.HasPrefix(plain, head+status) {
+			return plain
+		}
+		tail = plain[len(head)+len(status):]
+	}
+	return head + StatusColorizer(msg.ResponseStatus)("%s", status) + tail
+}
+
+// repeatColor renders the counter of collapsed duplicates
+var repeatColor = NewColor(color.Faint).SprintfFunc()
+
+// formatRepeatedFor renders the time span of collapsed duplicates, e.g. "0.4s" or "1m12s"
+func formatRepeatedFor(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// matchHighlight marks text matching --grep
+var matchHighlight = NewColor(color.ReverseVideo).SprintFunc()
+
+// ansiRegex matches color codes
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// highlightMatches marks all matches of the expressions in s. Matches overlapping color codes are not marked.
+func highlightMatches(s string, expressions []*regexp.Regexp) string {
+	codes := ansiRegex.FindAllStringIndex(s, -1)
+	overlapsCode := func(span []int) bool {
+		for _, code := range codes {
+			if span[0] < code[1] && code[0] < span[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var spans [][]int
+	for _, re := range expressions {
+		for _, span := range re.FindAllStringIndex(s, -1) {
+			if span[0] < span[1] && !overlapsCode(span) {
+				spans = append(spans, span)
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return s
+	}
+	slices.SortFunc(spans, func(a, b []int) int { return a[0] - b[0] })
+
+	var sb strings.Builder
+	pos := 0
+	for _, span := range spans {
+		start := max(span[0], pos)
+		if start >= span[1] {
+			continue // covered by the previous match
+		}
+		sb.WriteString(s[pos:start])
+		sb.WriteString(matchHighlight(s[start:span[1]]))
+		pos = span[1]
+	}
+	sb.WriteString(s[pos:])
+	return sb.String()
+}
+
+// shortenMiddle pads or shortens input to exactly width characters, replacing the middle by "..." if there is room
+func shortenMiddle(input string, width int) string {
+	width = max(width, 0)
+	runes := []rune(input)
+	if len(runes) <= width {
+		// Pad with spaces if shorter than width
+		return fmt.Sprintf("%-*s", width, input)
+	}
+
+	dots := "..."
+	if width <= len(dots) {
+		return string(runes[:width])
 	}
 
 	// Length of prefix and suffix we want to keep
 	// (e.g. 18 + 3 + 19 = 40)
-	dots := "..."
-	keep := (max - len(dots)) / 2
-	start := input[:keep]
-	end := input[len(input)-(max-len(dots)-keep):]
+	keep := (width - len(dots)) / 2
+	start := string(runes[:keep])
+	end := string(runes[len(runes)-(width-len(dots)-keep):])
 
 	return start + dots + end
 }
@@ -103,18 +330,49 @@ var NoColor = func(format string, a ...interface{}) string {
 }
 */
 
-// LevelColorizer returns a color formatting function for the given log level
+// LevelColorizer returns a color formatting function for the given log level, in the colors of the theme
 func LevelColorizer(level string) ColorFunc {
-	switch level {
-	case "ERROR":
-		return color.New(color.FgRed).Add(color.Bold).SprintfFunc()
-	case "WARN":
-		return color.New(color.FgYellow).Add(color.Bold).SprintfFunc()
-	case "INFO":
-		return color.New(color.FgCyan).Add(color.Bold).SprintfFunc()
-	case "DEBUG":
-		return color.New(color.FgHiBlack).Add(color.Bold).SprintfFunc()
+	if c := theme.levels[level]; c != nil {
+		return c.SprintfFunc()
+	}
+	return NoColor()
+}
+
+// StatusColorizer returns a color formatting function for the given HTTP status code
+func StatusColorizer(status int) ColorFunc {
+	switch {
+	case status >= 500:
+		return NewColor(color.FgRed, color.Bold).SprintfFunc()
+	case status >= 400:
+		return NewColor(color.FgYellow).SprintfFunc()
+	case status >= 300:
+		return NewColor(color.FgCyan).SprintfFunc()
+	case status >= 200:
+		return NewColor(color.FgGreen).SprintfFunc()
 	default:
 		return NoColor()
 	}
 }
+
+// LabelColorizer returns a color formatting function for an input label. The same label always gets the same color
+// of the source colors of the theme.
+func LabelColorizer(label string) ColorFunc {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.TrimSpace(label)))
+	return theme.sources[h.Sum32()%uint32(len(theme.sources))].SprintfFunc()
+}
+
+// SourceColorizer returns a color formatting function for a CF source. The same source always gets the same color,
+// consecutive instances of a process get different colors.
+func SourceColorizer(source string) ColorFunc {
+	base, instance := source, 0
+	if i := strings.LastIndex(source, "/"); i >= 0 {
+		if index, err := strconv.Atoi(source[i+1:]); err == nil {
+			base, instance = source[:i], index
+		}
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(base))
+	return theme.sources[(h.Sum32()+uint32(instance))%uint32(len(theme.sources))].SprintfFunc()
+}
@@ -6,9 +6,13 @@
 package formatter
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/saschakiefer/cf-log-pretty/internal/config"
 	"github.com/saschakiefer/cf-log-pretty/internal/parser"
 )
@@ -165,3 +169,306 @@ func TestFormat_LoggerNameOnlyDisabled(t *testing.T) {
 		t.Errorf("Expected full logger name in output, got: %s", output)
 	}
 }
+
+func TestFormat_RequestLog(t *testing.T) {
+	msg := &parser.LogMessage{
+		Timestamp:      "2024-01-01T12:00:00.00",
+		Level:          "INFO",
+		Logger:         "com.sap.hcp.cf.logging.servlet.filter.RequestLogger",
+		Type:           "request",
+		Method:         "GET",
+		Request:        "/api/orders?id=1",
+		ResponseStatus: 200,
+		ResponseTimeMs: 12.4,
+	}
+
+	output := Format(msg, LevelColorizer(msg.Level), &config.Config{})
+
+	if !strings.HasSuffix(output, ": GET /api/orders?id=1 -> 200 (12ms)") {
+		t.Errorf("Expected request summary as message, got: %s", output)
+	}
+}
+
+func TestFormat_RouterAccessLog(t *testing.T) {
+	msg := &parser.LogMessage{
+		Timestamp:      "2024-01-01T12:00:00.00",
+		Level:          "-----",
+		Type:           "router",
+		Host:           "orders.cfapps.eu10.hana.ondemand.com",
+		Method:         "GET",
+		Request:        "/api/orders",
+		ResponseStatus: 503,
+		ResponseSizeB:  67,
+		ResponseTimeMs: 31.457,
+		AppIndex:       "2",
+		UserAgent:      "curl/8.4.0",
+		Message:        `orders.cfapps.eu10.hana.ondemand.com - [2024-01-01T11:00:00.0Z] "GET /api/orders HTTP/1.1" 503 ...`,
+	}
+
+	output := Format(msg, LevelColorizer(msg.Level), &config.Config{})
+
+	if !strings.Contains(output, "orders.cfapps.eu10.hana.ondemand.com     : ") {
+		t.Errorf("Expected host in logger column, got: %s", output)
+	}
+	if !strings.HasSuffix(output, `: GET /api/orders -> 503 67B 31ms app#2 "curl/8.4.0"`) {
+		t.Errorf("Expected compact access log, got: %s", output)
+	}
+}
+
+func TestFormat_RouterAccessLogTruncated(t *testing.T) {
+	msg := &parser.LogMessage{
+		Timestamp:      "2024-01-01T12:00:00.00",
+		Level:          "-----",
+		Type:           "router",
+		Host:           "orders.cfapps.eu10.hana.ondemand.com",
+		Method:         "GET",
+		Request:        "/api/orders",
+		ResponseStatus: 200,
+		UserAgent:      "curl/8.4.0",
+	}
+
+	output := Format(msg, LevelColorizer(msg.Level), &config.Config{TruncateRaw: true})
+
+	if len(output) != 80 {
+		t.Errorf("Expected output length of 80 characters, got: %d: %s", len(output), output)
+	}
+	if !strings.HasSuffix(output, ": GET...") {
+		t.Errorf("Expected truncated access log, got: %s", output)
+	}
+}
+
+func TestStatusColorizer(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	tests := []struct {
+		status int
+		code   string
+	}{
+		{200, "32"},
+		{302, "36"},
+		{404, "33"},
+		{503, "31;1"},
+	}
+
+	for _, tt := range tests {
+		output := StatusColorizer(tt.status)("%d", tt.status)
+		expected := fmt.Sprintf("\x1b[%sm%d\x1b[0", tt.code, tt.status)
+		if !strings.HasPrefix(output, expected) {
+			t.Errorf("Expected %q for status %d, got %q", expected, tt.status, output)
+		}
+	}
+
+	if output := StatusColorizer(0)("%d", 0); output != "0" {
+		t.Errorf("Expected no color for status 0, got %q", output)
+	}
+}
+
+func TestFormat_Label(t *testing.T) {
+	msg := &parser.LogMessage{
+		Timestamp: "2024-01-01T12:00:00.00",
+		Level:     "INFO",
+		Logger:    "com.example.MyLogger",
+		Message:   "Test message",
+		Label:     "app-a",
+	}
+
+	output := Format(msg, LevelColorizer(msg.Level), &config.Config{})
+
+	if !strings.HasPrefix(output, "app-a 2024-01-01T12:00:00.00 [INFO ]") {
+		t.Errorf("Expected label in front of the line, got: %s", output)
+	}
+}
+
+func TestFormat_ShowSource(t *testing.T) {
+	msg := &parser.LogMessage{
+		Timestamp: "2024-01-01T12:00:00.00",
+		Source:    "APP/PROC/WEB/2",
+		Level:     "INFO",
+		Logger:    "com.example.MyLogger",
+		Message:   "Test message",
+	}
+
+	output := Format(msg, LevelColorizer(msg.Level), &config.Config{ShowSource: true})
+	if !strings.HasPrefix(output, "2024-01-01T12:00:00.00 [INFO ] WEB/2    com.example.MyLogger") {
+		t.Errorf("Expected source column after the level, got: %s", output)
+	}
+
+	output = Format(msg, LevelColorizer(msg.Level), &config.Config{})
+	if strings.Contains(output, "WEB/2") {
+		t.Errorf("Expected no source column by default, got: %s", output)
+	}
+}
+
+func TestSourceColorizer(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	if SourceColorizer("APP/PROC/WEB/0")("x") != SourceColorizer("APP/PROC/WEB/0")("x") {
+		t.Error("Expected the same color for the same source")
+	}
+	for i := 0; i < len(theme.sources)-1; i++ {
+		a := SourceColorizer(fmt.Sprintf("APP/PROC/WEB/%d", i))("x")
+		b := SourceColorizer(fmt.Sprintf("APP/PROC/WEB/%d", i+1))("x")
+		if a == b {
+			t.Errorf("Expected different colors for instances %d and %d", i, i+1)
+		}
+	}
+}
+
+func TestFormat_GrepHighlight(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	msg := &parser.LogMessage{
+		Timestamp:  "2024-01-01T12:00:00.00",
+		Level:      "ERROR",
+		Logger:     "com.example.MyLogger",
+		Message:    "Request timed out, timed out again",
+		StackTrace: []string{"java.net.SocketTimeoutException: Read timed out"},
+	}
+
+	output := Format(msg, NoColor(), &config.Config{Grep: []string{"timed out", "/again$/"}})
+	expected := "Request \x1b[7mtimed out\x1b[27m, \x1b[7mtimed out\x1b[27m \x1b[7magain\x1b[27m"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected highlighted matches in message, got: %q", output)
+	}
+	if !strings.HasSuffix(output, "Read \x1b[7mtimed out\x1b[27m") {
+		t.Errorf("Expected highlighted match in stack trace, got: %q", output)
+	}
+
+	color.NoColor = true
+	output = Format(msg, NoColor(), &config.Config{Grep: []string{"timed out"}})
+	if strings.Contains(output, "\x1b") {
+		t.Errorf("Expected no highlighting without colors, got: %q", output)
+	}
+}
+
+func TestHighlightMatches_Overlapping(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	re1, _ := regexp.Compile("abc")
+	re2, _ := regexp.Compile("bcd")
+	if got := highlightMatches("xabcdx", []*regexp.Regexp{re1, re2}); got != "x\x1b[7mabc\x1b[27m\x1b[7md\x1b[27mx" {
+		t.Errorf("Expected overlapping matches to be highlighted once, got: %q", got)
+	}
+
+	colored := "GET / -> \x1b[32m200\x1b[27m"
+	re3, _ := regexp.Compile(`32m200`)
+	if got := highlightMatches(colored, []*regexp.Regexp{re3}); got != colored {
+		t.Errorf("Expected matches within color codes to be skipped, got: %q", got)
+	}
+}
+
+func TestFormat_Repeated(t *testing.T) {
+	msg := &parser.LogMessage{
+		Timestamp:   "2024-01-01T12:00:00.00",
+		Level:       "INFO",
+		Logger:      "com.example.Health",
+		Message:     "health check ok",
+		Repeated:    37,
+		RepeatedFor: 12*time.Second + 300*time.Millisecond,
+	}
+
+	output := Format(msg, NoColor(), &config.Config{})
+	if !strings.HasSuffix(output, ": health check ok (×37 in 12s)") {
+		t.Errorf("Expected repetition counter, got: %s", output)
+	}
+
+	msg.RepeatedFor = 400 * time.Millisecond
+	output = Format(msg, NoColor(), &config.Config{})
+	if !strings.HasSuffix(output, "(×37 in 0.4s)") {
+		t.Errorf("Expected repetition counter with fractional seconds, got: %s", output)
+	}
+
+	msg.Repeated = 1
+	output = Format(msg, NoColor(), &config.Config{})
+	if strings.Contains(output, "×") {
+		t.Errorf("Expected no counter for a single message, got: %s", output)
+	}
+}
+
+func TestLabelColorizer_Stable(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	if LabelColorizer("app-a")("x") != LabelColorizer("app-a  ")("x") {
+		t.Error("Expected the same color for the same (padded) label")
+	}
+}
+
+func TestFormat_TimeFormat(t *testing.T) {
+	msg := &parser.LogMessage{
+		Timestamp: "2024-01-01T13:00:00.12",
+		Time:      time.Date(2024, 1, 1, 12, 0, 0, 123_000_000, time.UTC),
+		Level:     "INFO",
+		Message:   "Test message",
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"", "2024-01-01T13:00:00.12 "},
+		{"cf", "2024-01-01T13:00:00.12 "},
+		{"utc", "2024-01-01 12:00:00.123Z "},
+		{"2006/01/02 15:04", msg.Time.Local().Format("2006/01/02 15:04") + " "},
+		{"time-only", msg.Time.Local().Format("15:04:05.000") + " "},
+		{"rfc3339", msg.Time.Local().Format("2006-01-02T15:04:05.000Z07:00") + " "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			output := Format(msg, LevelColorizer(msg.Level), &config.Config{TimeFormat: tt.format})
+			if !strings.HasPrefix(output, tt.expected+"[INFO ]") {
+				t.Errorf("Expected output to start with %q, got: %s", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestFormat_RelativeTime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{TimeFormat: "relative"}
+	pretty := &Pretty{cfg: cfg}
+
+	expected := []struct {
+		offset time.Duration
+		prefix string
+	}{
+		{0, "   +0.000s "},
+		{1234 * time.Millisecond, "   +1.234s "},
+		{1 * time.Second, "   -0.234s "},
+	}
+
+	for _, e := range expected {
+		msg := &parser.LogMessage{Time: start.Add(e.offset), Level: "INFO"}
+		if output := pretty.Format(msg); !strings.HasPrefix(output, e.prefix) {
+			t.Errorf("Expected output to start with %q, got: %s", e.prefix, output)
+		}
+
+		// Format itself doesn't depend on previous calls
+		if output := Format(msg, LevelColorizer(msg.Level), cfg); !strings.HasPrefix(output, "   +0.000s ") {
+			t.Errorf("Expected Format without previous time to start with %q, got: %s", "   +0.000s ", output)
+		}
+	}
+}
+
+func TestValidateTimeFormat(t *testing.T) {
+	valid := []string{"", "cf", "local", "utc", "rfc3339", "time-only", "relative", "15:04:05", "Jan 2 15:04:05"}
+	for _, format := range valid {
+		if err := ValidateTimeFormat(format); err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", format, err)
+		}
+	}
+
+	if err := ValidateTimeFormat("foo"); err == nil {
+		t.Error("Expected 'foo' to be invalid")
+	}
+}
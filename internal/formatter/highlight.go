@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// durationUnits are the units of durations like "31ms", "1m30s" or "3 seconds"
+const durationUnits = `(?:ns|us|µs|ms|millis|milliseconds|s|secs?|seconds?|m|mins?|minutes?|h|hours?)`
+
+// tokenRegex matches the tokens of --highlight. Alternatives starting at the same position are tried in order.
+// Groups without a color (the prefixes) are part of the match but not colored.
+var tokenRegex = regexp.MustCompile(strings.Join([]string{
+	`(?P<string>"[^"\n]*")`,
+	`(?P<quotePrefix>(?:^|[^\w']))(?P<quoted>'[^'\n]*')`,
+	`(?P<url>\bhttps?://[^\s"'<>]+)`,
+	`(?P<uuid>\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b)`,
+	`(?P<hexID>\b[0-9a-fA-F]{16,}\b)`,
+	`(?P<exception>\b(?:[a-z_][\w$]*\.)*[A-Z][\w$]*(?:Exception|Error|Throwable)\b)`,
+	`(?P<method>\b(?:GET|HEAD|POST|PUT|PATCH|DELETE|OPTIONS)\b)`,
+	`(?P<statusPrefix>\b(?i:status)[=:]\s*|\bHTTP/\d(?:\.\d)?\s+|->\s*)(?P<status>[1-5]\d\d)\b`,
+	`(?P<timestamp>\b\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?)`,
+	`(?P<ip>\b(?:\d{1,3}\.){3}\d{1,3}(?::\d{1,5})?\b)`,
+	`(?P<duration>\b\d+(?:\.\d+)?(?:` + durationUnits + `(?:\d+(?:\.\d+)?` + durationUnits + `)*|\s` + durationUnits + `)\b)`,
+	`(?P<number>\b\d+(?:\.\d+)?\b)`,
+}, "|"))
+
+// tokenColors colors the tokens of the named groups of tokenRegex
+var tokenColors = map[string]func(a ...interface{}) string{
+	"string":    NewColor(color.FgGreen).SprintFunc(),
+	"quoted":    NewColor(color.FgGreen).SprintFunc(),
+	"url":       NewColor(color.FgBlue, color.Underline).SprintFunc(),
+	"uuid":      idColor,
+	"hexID":     idColor,
+	"exception": NewColor(color.FgHiRed).SprintFunc(),
+	"method":    NewColor(color.Bold).SprintFunc(),
+	"status": func(a ...interface{}) string {
+		status, _ := strconv.Atoi(fmt.Sprint(a...))
+		return StatusColorizer(status)("%d", status)
+	},
+	"timestamp": NewColor(color.FgMagenta).SprintFunc(),
+	"ip":        NewColor(color.FgCyan).SprintFunc(),
+	"duration":  NewColor(color.FgYellow).SprintFunc(),
+	"number":    NewColor(color.FgMagenta).SprintFunc(),
+}
+
+// idColor colors IDs like correlation IDs by their value, so the same ID has the same color in all logs
+func idColor(a ...interface{}) string {
+	id := fmt.Sprint(a...)
+	return LabelColorizer(strings.ToLower(id))("%s", id)
+}
+
+// highlightTokens colors UUIDs and other IDs, URLs, HTTP methods and status codes, timestamps, IP addresses,
+// durations, numbers, quoted strings and exception class names in s. Parts of s that are colored already are kept.
+func highlightTokens(s string) string {
+	if color.NoColor {
+		return s
+	}
+	return mapUncolored(s, highlightPlainTokens)
+}
+
+// mapUncolored replaces the parts of s outside colored parts by the result of fn
+func mapUncolored(s string, fn func(string) string) string {
+	var sb strings.Builder
+	pos, colored := 0, false
+	uncolored := func(part string) string {
+		if colored || part == "" {
+			return part
+		}
+		return fn(part)
+	}
+
+	for _, code := range ansiRegex.FindAllStringIndex(s, -1) {
+		sb.WriteString(uncolored(s[pos:code[0]]))
+		sb.WriteString(s[code[0]:code[1]])
+		colored = !isReset(s[code[0]:code[1]])
+		pos = code[1]
+	}
+	sb.WriteString(uncolored(s[pos:]))
+	return sb.String()
+}
+
+// isReset reports whether a color code ends the colored part, e.g. "\x1b[0m", "\x1b[0;22m" or "\x1b[22m"
+func isReset(code string) bool {
+	params := strings.TrimSuffix(strings.TrimPrefix(code, "\x1b["), "m")
+	first, _, _ := strings.Cut(params, ";")
+	return first == "" || first == "0" || first == "39" || first == "49" || (len(first) == 2 && first[0] == '2')
+}
+
+// highlightPlainTokens colors the tokens of s, which must not contain color codes
+func highlightPlainTokens(s string) string {
+	var sb strings.Builder
+	pos := 0
+	for _, match := range tokenRegex.FindAllStringSubmatchIndex(s, -1) {
+		for i, name := range tokenRegex.SubexpNames() {
+			colorize, ok := tokenColors[name]
+			if !ok || match[2*i] < 0 {
+				continue
+			}
+			sb.WriteString(s[pos:match[2*i]])
+			sb.WriteString(colorize(s[match[2*i]:match[2*i+1]]))
+			pos = match[2*i+1]
+			break
+		}
+	}
+	sb.WriteString(s[pos:])
+	return sb.String()
+}
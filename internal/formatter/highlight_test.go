@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// coloredRegex matches a colored part of a string
+var coloredRegex = regexp.MustCompile(`\x1b\[[0-9;]+m([^\x1b]*)\x1b\[[0-9;]*m`)
+
+// coloredParts returns the colored parts of s
+func coloredParts(s string) []string {
+	var parts []string
+	for _, m := range coloredRegex.FindAllStringSubmatch(s, -1) {
+		parts = append(parts, m[1])
+	}
+	return parts
+}
+
+func TestHighlightTokens(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"UUID", "request d2b5f4a0-8c1e-4c57-a3ab-6a3c0e8f2d11 done", []string{"d2b5f4a0-8c1e-4c57-a3ab-6a3c0e8f2d11"}},
+		{"Hex ID", "trace 4bf92f3577b34da6a3ce929d0e0e4736, span 00f067aa0ba902b7", []string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"}},
+		{"URL", "calling https://example.com/v1/pay?id=7 now", []string{"https://example.com/v1/pay?id=7"}},
+		{"HTTP method and status", "POST /orders -> 201, GET /x status=404, HTTP/1.1 503", []string{"POST", "201", "GET", "404", "503"}},
+		{"Durations", "took 31ms, retry in 1m30s or 3 seconds", []string{"31ms", "1m30s", "3 seconds"}},
+		{"Numbers", "42 items of 1.5 kg, not v2 or Client.java:17", []string{"42", "1.5", "17"}},
+		{"Quoted strings", `user "jane doe" and 'A-17', but don't`, []string{`"jane doe"`, "'A-17'"}},
+		{"IP address", "from 10.0.1.17:8080 and 192.168.0.1", []string{"10.0.1.17:8080", "192.168.0.1"}},
+		{"Timestamp", "since 2024-05-01T14:00:00.123Z", []string{"2024-05-01T14:00:00.123Z"}},
+		{"Exception classes", "java.lang.IllegalStateException: caused by OutOfMemoryError, no Error", []string{"java.lang.IllegalStateException", "OutOfMemoryError"}},
+		{"Tokens within strings are not colored separately", `body "id 42"`, []string{`"id 42"`}},
+		{"No tokens", "Started application", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := highlightTokens(tt.input)
+
+			if parts := coloredParts(got); !slices.Equal(parts, tt.expected) {
+				t.Errorf("Expected colored parts %q, got %q (%q)", tt.expected, parts, got)
+			}
+			if plain := ansiRegex.ReplaceAllString(got, ""); plain != tt.input {
+				t.Errorf("Expected text to be unchanged, got %q", plain)
+			}
+		})
+	}
+}
+
+func TestHighlightTokens_KeepsColoredParts(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	input := "GET /orders -> " + StatusColorizer(503)("%d", 503) + " 31ms"
+	expected := "\x1b[1mGET\x1b[22m /orders -> \x1b[31;1m503\x1b[0;22m \x1b[33m31ms\x1b[0m"
+
+	if got := highlightTokens(input); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestHighlightTokens_SameIDSameColor(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	id := "d2b5f4a0-8c1e-4c57-a3ab-6a3c0e8f2d11"
+	first, second := highlightTokens("a "+id), highlightTokens("b "+id)
+	if first[1:] != second[1:] {
+		t.Errorf("Expected the same color for the same ID, got %q and %q", first, second)
+	}
+}
+
+func TestFormat_Highlight_NoColor(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = origNoColor }()
+
+	msg := &parser.LogMessage{
+		Timestamp:  "2024-05-01T14:00:00.00",
+		Level:      "ERROR",
+		Logger:     "com.acme.Client",
+		Message:    "POST https://example.com failed with status=503 after 31ms",
+		StackTrace: []string{"java.lang.IllegalStateException: boom"},
+	}
+
+	output := Format(msg, NoColor(), &config.Config{Highlight: true})
+	expected := "2024-05-01T14:00:00.00 [ERROR] com.acme.Client                          : POST https://example.com failed with status=503 after 31ms\n" +
+		"    java.lang.IllegalStateException: boom"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
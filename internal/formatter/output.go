@@ -0,0 +1,218 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// Outputs lists the supported values of the --output flag
+var Outputs = []string{"pretty", "jsonl", "logfmt", "csv"}
+
+// Formatter renders a log message as one output record (which may span several lines)
+type Formatter interface {
+	Format(msg *parser.LogMessage) string
+}
+
+// New returns the formatter for the given output format
+func New(output string, cfg *config.Config) (Formatter, error) {
+	switch output {
+	case "", "pretty":
+		return &Pretty{cfg: cfg}, nil
+	case "jsonl":
+		return &JSONLines{}, nil
+	case "logfmt":
+		return &Logfmt{}, nil
+	case "csv":
+		return &CSV{}, nil
+	default:
+		return nil, fmt.Errorf("invalid output format: %s (allowed: %s)", output, strings.Join(Outputs, ", "))
+	}
+}
+
+// Pretty is the colored, human-readable output
+type Pretty struct {
+	cfg      *config.Config
+	previous time.Time // of the previously formatted message, for relative timestamps
+}
+
+func (p *Pretty) Format(msg *parser.LogMessage) string {
+	result := FormatAfter(msg, LevelColorizer(msg.Level), p.cfg, p.previous)
+	if !msg.Time.IsZero() {
+		p.previous = msg.Time
+	}
+	return result
+}
+
+// record holds the normalised fields of a log message, shared by the structured outputs
+type record struct {
+	Time       string         `json:"time"`
+	Label      string         `json:"label,omitempty"`
+	Source     string         `json:"source,omitempty"`
+	Direction  string         `json:"direction,omitempty"`
+	Level      string         `json:"level,omitempty"`
+	Logger     string         `json:"logger,omitempty"`
+	Message    string         `json:"message"`
+	StackTrace []string       `json:"stacktrace,omitempty"`
+	Repeated   int            `json:"repeated,omitempty"`
+	Fields     map[string]any `json:"fields,omitempty"`
+}
+
+// normalisedFields are part of the record itself and not repeated in its extra fields
+var normalisedFields = []string{"written_at", "level", "logger", "msg", "stacktrace", "#cf", "custom_fields"}
+
+func newRecord(msg *parser.LogMessage) record {
+	r := record{
+		Time:       msg.Timestamp,
+		Label:      strings.TrimSpace(msg.Label),
+		Source:     msg.Source,
+		Direction:  msg.Direction,
+		Level:      msg.Level,
+		Logger:     msg.Logger,
+		Message:    msg.Message,
+		StackTrace: msg.StackTrace,
+	}
+
+	if msg.Repeated > 1 {
+		r.Repeated = msg.Repeated
+	}
+
+	if !msg.Time.IsZero() {
+		r.Time = msg.Time.Format(time.RFC3339Nano)
+	}
+	if r.Level == "-----" {
+		r.Level = ""
+	}
+
+	if len(msg.Fields) > 0 || len(msg.CustomFields) > 0 {
+		r.Fields = map[string]any{}
+		for k, v := range msg.Fields {
+			if !slices.Contains(normalisedFields, k) {
+				r.Fields[k] = v
+			}
+		}
+		for k, v := range msg.CustomFields {
+			r.Fields[k] = v
+		}
+	}
+
+	return r
+}
+
+// JSONLines renders one JSON object per line
+type JSONLines struct{}
+
+func (j *JSONLines) Format(msg *parser.LogMessage) string {
+	data, err := json.Marshal(newRecord(msg))
+	if err != nil {
+		// Only possible for unsupported values in fields, which JSON input can't produce
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	return string(data)
+}
+
+// Logfmt renders key=value pairs, with the extra fields sorted by name
+type Logfmt struct{}
+
+func (l *Logfmt) Format(msg *parser.LogMessage) string {
+	r := newRecord(msg)
+
+	pairs := [][2]string{
+		{"time", r.Time},
+		{"label", r.Label},
+		{"source", r.Source},
+		{"direction", r.Direction},
+		{"level", r.Level},
+		{"logger", r.Logger},
+		{"msg", r.Message},
+		{"stacktrace", strings.Join(r.StackTrace, "\n")},
+	}
+	if r.Repeated > 0 {
+		pairs = append(pairs, [2]string{"repeated", strconv.Itoa(r.Repeated)})
+	}
+	for _, k := range slices.Sorted(maps.Keys(r.Fields)) {
+		pairs = append(pairs, [2]string{k, fieldString(r.Fields[k])})
+	}
+
+	var sb strings.Builder
+	for _, pair := range pairs {
+		if pair[1] == "" && pair[0] != "msg" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(pair[0] + "=" + logfmtValue(pair[1]))
+	}
+	return sb.String()
+}
+
+// logfmtValue quotes value if it contains characters that would break the key=value syntax
+func logfmtValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " =\"\\") || strings.IndexFunc(value, func(r rune) bool { return r < 0x20 }) >= 0 {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// CSV renders comma separated values, preceded by a header row before the first record
+type CSV struct {
+	headerWritten bool
+}
+
+var csvHeader = []string{"time", "label", "source", "direction", "level", "logger", "message", "stacktrace", "repeated", "fields"}
+
+func (c *CSV) Format(msg *parser.LogMessage) string {
+	r := newRecord(msg)
+
+	repeated := ""
+	if r.Repeated > 0 {
+		repeated = strconv.Itoa(r.Repeated)
+	}
+
+	fields := ""
+	if len(r.Fields) > 0 {
+		data, _ := json.Marshal(r.Fields)
+		fields = string(data)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if !c.headerWritten {
+		_ = w.Write(csvHeader)
+		c.headerWritten = true
+	}
+	_ = w.Write([]string{r.Time, r.Label, r.Source, r.Direction, r.Level, r.Logger, r.Message, strings.Join(r.StackTrace, "\n"), repeated, fields})
+	w.Flush()
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// fieldString renders an extra field value, using JSON for nested values
+func fieldString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
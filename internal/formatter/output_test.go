@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// helper to build a message with stack trace and extra fields
+func structuredMsg() *parser.LogMessage {
+	return &parser.LogMessage{
+		Timestamp:     "2024-01-01T13:00:00.12",
+		Time:          time.Date(2024, 1, 1, 12, 0, 0, 123_000_000, time.UTC),
+		Source:        "APP/PROC/WEB/0",
+		Direction:     "OUT",
+		Level:         "ERROR",
+		Logger:        "com.example.MyLogger",
+		Message:       `Order "42" failed`,
+		StackTrace:    []string{"java.lang.Exception", "\tat com.example.Class.method(Class.java:42)"},
+		CorrelationID: "abc",
+		CustomFields:  map[string]string{"order_id": "42"},
+		Fields: map[string]any{
+			"level":          "ERROR",
+			"msg":            `Order "42" failed`,
+			"correlation_id": "abc",
+			"response_time":  json.Number("12.5"),
+			"#cf":            map[string]any{},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, output := range Outputs {
+		if _, err := New(output, &config.Config{}); err != nil {
+			t.Errorf("Expected output %s to be supported, got: %v", output, err)
+		}
+	}
+
+	_, err := New("xml", &config.Config{})
+	if err == nil || err.Error() != "invalid output format: xml (allowed: pretty, jsonl, logfmt, csv)" {
+		t.Errorf("Unexpected error for unknown output: %v", err)
+	}
+}
+
+func TestJSONLines_Format(t *testing.T) {
+	output := (&JSONLines{}).Format(structuredMsg())
+
+	expected := `{"time":"2024-01-01T12:00:00.123Z","source":"APP/PROC/WEB/0","direction":"OUT","level":"ERROR","logger":"com.example.MyLogger","message":"Order \"42\" failed","stacktrace":["java.lang.Exception","\tat com.example.Class.method(Class.java:42)"],"fields":{"correlation_id":"abc","order_id":"42","response_time":12.5}}`
+	if output != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, output)
+	}
+}
+
+func TestJSONLines_FormatPlainMessage(t *testing.T) {
+	output := (&JSONLines{}).Format(&parser.LogMessage{Timestamp: "", Level: "-----", Message: "plain"})
+
+	if output != `{"time":"","message":"plain"}` {
+		t.Errorf("Unexpected output: %s", output)
+	}
+}
+
+func TestStructured_FormatRepeated(t *testing.T) {
+	msg := &parser.LogMessage{Level: "INFO", Message: "health check ok", Repeated: 37}
+
+	if output := (&JSONLines{}).Format(msg); output != `{"time":"","level":"INFO","message":"health check ok","repeated":37}` {
+		t.Errorf("Unexpected JSON output: %s", output)
+	}
+	if output := (&Logfmt{}).Format(msg); output != `level=INFO msg="health check ok" repeated=37` {
+		t.Errorf("Unexpected logfmt output: %s", output)
+	}
+	if output := (&CSV{headerWritten: true}).Format(msg); output != `,,,,INFO,,health check ok,,37,` {
+		t.Errorf("Unexpected CSV output: %s", output)
+	}
+
+	msg.Repeated = 1
+	if output := (&JSONLines{}).Format(msg); strings.Contains(output, "repeated") {
+		t.Errorf("Expected no repetitions for a single message, got: %s", output)
+	}
+}
+
+func TestLogfmt_Format(t *testing.T) {
+	output := (&Logfmt{}).Format(structuredMsg())
+
+	expected := `time=2024-01-01T12:00:00.123Z source=APP/PROC/WEB/0 direction=OUT level=ERROR logger=com.example.MyLogger msg="Order \"42\" failed" stacktrace="java.lang.Exception\n\tat com.example.Class.method(Class.java:42)" correlation_id=abc order_id=42 response_time=12.5`
+	if output != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, output)
+	}
+
+	if output := (&Logfmt{}).Format(&parser.LogMessage{Level: "-----"}); output != `msg=""` {
+		t.Errorf("Expected empty message to be quoted, got: %s", output)
+	}
+}
+
+func TestCSV_Format(t *testing.T) {
+	c := &CSV{}
+
+	first := c.Format(structuredMsg())
+	lines := strings.SplitN(first, "\n", 2)
+	if lines[0] != "time,label,source,direction,level,logger,message,stacktrace,repeated,fields" {
+		t.Errorf("Expected header before the first record, got: %s", lines[0])
+	}
+
+	expected := `2024-01-01T12:00:00.123Z,,APP/PROC/WEB/0,OUT,ERROR,com.example.MyLogger,"Order ""42"" failed","java.lang.Exception` + "\n" + `	at com.example.Class.method(Class.java:42)",,"{""correlation_id"":""abc"",""order_id"":""42"",""response_time"":12.5}"`
+	if lines[1] != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, lines[1])
+	}
+
+	second := c.Format(&parser.LogMessage{Level: "INFO", Message: "next", Label: "app-a  "})
+	if second != ",app-a,,,INFO,,next,,," {
+		t.Errorf("Expected no header for the second record, got: %s", second)
+	}
+}
+
+func TestPretty_Format(t *testing.T) {
+	msg := structuredMsg()
+	cfg := &config.Config{}
+
+	if (&Pretty{cfg: cfg}).Format(msg) != Format(msg, LevelColorizer(msg.Level), cfg) {
+		t.Error("Expected pretty output to match Format")
+	}
+}
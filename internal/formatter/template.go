@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// templates caches the compiled --template texts
+var templates sync.Map
+
+// templateData is passed to a --template. Besides all fields of the log message, it carries the
+// processed values of the default layout.
+type templateData struct {
+	*parser.LogMessage
+	Timestamp string // formatted according to --time-format
+	Logger    string // with --remove-logger-prefix / --show-logger-name-only applied, the host for router logs
+	Message   string // with summaries of request and router logs and --truncate-raw applied
+}
+
+// templateFuncs are the helper functions available in templates
+var templateFuncs = template.FuncMap{
+	// width pads or shortens (in the middle) a value to exactly n characters
+	"width": func(n int, value any) string {
+		return shortenMiddle(fmt.Sprint(value), n)
+	},
+	// pad pads a value with spaces on the right to at least n characters
+	"pad": func(n int, value any) string {
+		return fmt.Sprintf("%-*s", n, fmt.Sprint(value))
+	},
+	// lpad pads a value with spaces on the left to at least n characters
+	"lpad": func(n int, value any) string {
+		return fmt.Sprintf("%*s", n, fmt.Sprint(value))
+	},
+	// trunc cuts a value to at most n characters, ending with "..."
+	"trunc": func(n int, value any) string {
+		n = max(n, 0)
+		runes := []rune(fmt.Sprint(value))
+		if len(runes) <= n {
+			return string(runes)
+		}
+		if n <= 3 {
+			return string(runes[:n])
+		}
+		return string(runes[:n-3]) + "..."
+	},
+	// short renders a time as local time of day with milliseconds
+	"short": func(t time.Time) string {
+		if t.IsZero() {
+			return strings.Repeat(" ", 12)
+		}
+		return t.Local().Format("15:04:05.000")
+	},
+	// timefmt renders a time in local time using a Go time layout
+	"timefmt": func(layout string, t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format(layout)
+	},
+	// color renders a log level in its level color
+	"color": func(level string) string {
+		return LevelColorizer(strings.TrimSpace(level))("%s", level)
+	},
+	// colorBy renders a value in the color of the given log level
+	"colorBy": func(level string, value any) string {
+		return LevelColorizer(strings.TrimSpace(level))("%v", value)
+	},
+	// hashColor renders a value in a color derived from the value itself, e.g. for sources
+	"hashColor": func(value any) string {
+		return LabelColorizer(fmt.Sprint(value))("%v", value)
+	},
+	// sourceColor renders a value in the color of the given CF source, e.g. {{.ShortSource | sourceColor .Source}}
+	"sourceColor": func(source string, value any) string {
+		return SourceColorizer(source)("%v", value)
+	},
+	// default returns def if value is empty
+	"default": func(def string, value any) string {
+		if s := fmt.Sprint(value); value != nil && s != "" {
+			return s
+		}
+		return def
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// compileTemplate returns the compiled template for text, compiling it on first use
+func compileTemplate(text string) (*template.Template, error) {
+	if tmpl, ok := templates.Load(text); ok {
+		return tmpl.(*template.Template), nil
+	}
+
+	tmpl, err := template.New("template").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	templates.Store(text, tmpl)
+	return tmpl, nil
+}
+
+// ValidateTemplate checks that text is a valid template by rendering a sample message with it
+func ValidateTemplate(text string) error {
+	if text == "" {
+		return nil
+	}
+
+	tmpl, err := compileTemplate(text)
+	if err == nil {
+		err = tmpl.Execute(&strings.Builder{}, templateData{LogMessage: &parser.LogMessage{Time: time.Now(), Level: "INFO"}})
+	}
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	return nil
+}
+
+// renderTemplate renders the headline of a message with the given template
+func renderTemplate(text string, data templateData) string {
+	tmpl, err := compileTemplate(text)
+	if err != nil {
+		return "template error: " + err.Error()
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "template error: " + err.Error()
+	}
+	return sb.String()
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+func TestFormat_Template(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	msg := &parser.LogMessage{
+		Timestamp:     "2024-01-01T12:00:00.00+0000",
+		Time:          time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Source:        "APP/PROC/WEB/0",
+		Level:         "ERROR",
+		Logger:        "com.example.service.MyLogger",
+		Message:       "Something bad happened",
+		CorrelationID: "abc",
+		StackTrace:    []string{"java.lang.Exception"},
+	}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{{.Level}} {{.Message}}", "ERROR Something bad happened"},
+		{"{{.Logger | width 10}}|", "com...gger|"},
+		{"{{.Source | pad 16}}|", "APP/PROC/WEB/0  |"},
+		{"{{.Level | lpad 7}}|", "  ERROR|"},
+		{"{{.Message | trunc 12}}", "Something..."},
+		{"{{.Logger | width 3}}|", "com|"},
+		{"{{.Logger | width 2}}|", "co|"},
+		{"{{.Logger | width 1}}|", "c|"},
+		{"{{.Logger | width 0}}|", "|"},
+		{"{{.Logger | width -1}}|", "|"},
+		{"{{.Message | trunc 2}}", "So"},
+		{"{{.Message | trunc 0}}", ""},
+		{"{{.Message | trunc -1}}", ""},
+		{"{{\"héllo wörld\" | trunc 2}}", "hé"},
+		{"{{\"héllo wörld\" | trunc 8}}", "héllo..."},
+		{"{{\"héllo wörld\" | width 8}}|", "hé...rld|"},
+		{"{{\"héllo\" | width 7}}|", "héllo  |"},
+		{"{{.Level | lower}} {{.Level | color}}", "error ERROR"},
+		{"{{.Time | timefmt \"2006\"}}", "2024"},
+		{"{{.TenantID | default \"-\"}}", "-"},
+		{"{{if .CorrelationID}}[{{.CorrelationID}}]{{end}}", "[abc]"},
+		{"{{.Timestamp}}", "2024-01-01T12:00:00.00+0000"},
+	}
+
+	for _, tt := range tests {
+		output := Format(msg, LevelColorizer(msg.Level), &config.Config{Template: tt.template})
+		headline, rest, _ := strings.Cut(output, "\n")
+		if headline != tt.expected {
+			t.Errorf("Template %q: expected %q, got %q", tt.template, tt.expected, headline)
+		}
+		if rest != "    java.lang.Exception" {
+			t.Errorf("Template %q: expected stack trace to be appended, got %q", tt.template, rest)
+		}
+	}
+}
+
+func TestFormat_TemplateProcessedLogger(t *testing.T) {
+	msg := &parser.LogMessage{Level: "INFO", Logger: "com.example.service.MyLogger", Message: "m"}
+
+	output := Format(msg, NoColor(), &config.Config{Template: "{{.Logger}}", LoggerNameOnly: true})
+	if output != "MyLogger" {
+		t.Errorf("Expected processed logger name, got %q", output)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	valid := []string{"", "{{.Message}}", "{{.Time | short}} {{.Level | color}} {{.Source | hashColor}} {{.Logger | width 30}}"}
+	for _, text := range valid {
+		if err := ValidateTemplate(text); err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", text, err)
+		}
+	}
+
+	invalid := []string{"{{.Message", "{{.Level | foo}}", "{{.Nope}}", "{{width .Message 3}}"}
+	for _, text := range invalid {
+		if err := ValidateTemplate(text); err == nil {
+			t.Errorf("Expected %q to be invalid", text)
+		}
+	}
+}
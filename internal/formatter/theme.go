@@ -0,0 +1,310 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+)
+
+// DefaultTheme is the theme used without --theme
+const DefaultTheme = "dark"
+
+// Themes are the built-in themes
+var Themes = map[string]config.Theme{
+	"dark": {
+		Levels:  map[string]string{"ERROR": "red bold", "WARN": "yellow bold", "INFO": "cyan bold", "DEBUG": "hi-black bold"},
+		Sources: []string{"green", "magenta", "blue", "hi-cyan", "hi-yellow", "hi-magenta", "hi-green", "hi-blue"},
+	},
+	"light": {
+		Levels:     map[string]string{"ERROR": "red bold", "WARN": "130 bold", "INFO": "blue bold", "DEBUG": "244 bold", "TRACE": "250"},
+		Timestamp:  "244",
+		Sources:    []string{"28", "90", "25", "30", "130", "127", "22", "62"},
+		StackTrace: "244",
+	},
+	"solarized": {
+		Levels:     map[string]string{"ERROR": "#dc322f bold", "WARN": "#b58900 bold", "INFO": "#268bd2 bold", "DEBUG": "#586e75 bold", "TRACE": "#586e75"},
+		Timestamp:  "#586e75",
+		Logger:     "#93a1a1",
+		Sources:    []string{"#859900", "#d33682", "#268bd2", "#2aa198", "#b58900", "#6c71c4", "#cb4b16", "#93a1a1"},
+		StackTrace: "#657b83",
+	},
+	"high-contrast": {
+		Levels:     map[string]string{"ERROR": "hi-white bg:red bold", "WARN": "black bg:hi-yellow bold", "INFO": "hi-cyan bold", "DEBUG": "hi-white", "TRACE": "white"},
+		Timestamp:  "hi-white",
+		Logger:     "hi-white bold",
+		Sources:    []string{"hi-green", "hi-magenta", "hi-blue", "hi-cyan", "hi-yellow", "hi-white", "green", "magenta"},
+		StackTrace: "hi-yellow",
+	},
+}
+
+// Theme holds the compiled colors of a theme. Nil colors leave the text uncolored.
+type Theme struct {
+	levels     map[string]*color.Color
+	timestamp  *color.Color
+	logger     *color.Color
+	sources    []*color.Color
+	stackTrace *color.Color
+}
+
+// theme is the theme used for formatting
+var theme = mustCompileTheme(DefaultTheme)
+
+// UseTheme sets the theme used for formatting
+func UseTheme(t *Theme) {
+	theme = t
+}
+
+// CompileTheme compiles the user-defined or built-in theme with the given name. Truecolor colors are approximated
+// with 256 colors unless trueColor is set.
+func CompileTheme(name string, userThemes map[string]config.Theme, trueColor bool) (*Theme, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+
+	definition, ok := userThemes[name]
+	if ok {
+		base := definition.Base
+		if base == "" {
+			base = DefaultTheme
+		}
+		baseDefinition, ok := Themes[base]
+		if !ok {
+			return nil, fmt.Errorf("invalid theme %s: unknown base theme %s (allowed: %s)", name, base, strings.Join(slices.Sorted(maps.Keys(Themes)), ", "))
+		}
+		definition = mergeTheme(baseDefinition, definition)
+	} else if definition, ok = Themes[name]; !ok {
+		names := maps.Clone(Themes)
+		for userTheme := range userThemes {
+			names[userTheme] = config.Theme{}
+		}
+		available := slices.Sorted(maps.Keys(names))
+		return nil, fmt.Errorf("unknown theme: %s (available: %s)", name, strings.Join(available, ", "))
+	}
+
+	t := &Theme{levels: map[string]*color.Color{}}
+	compile := func(part string, spec string) (*color.Color, error) {
+		c, err := parseColor(spec, trueColor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid theme %s: %s: %w", name, part, err)
+		}
+		return c, nil
+	}
+
+	var err error
+	for level, spec := range definition.Levels {
+		level = strings.ToUpper(level)
+		if !themeLevels[level] {
+			return nil, fmt.Errorf("invalid theme %s: unknown level %s (allowed: TRACE, DEBUG, INFO, WARN, ERROR)", name, level)
+		}
+		if t.levels[level], err = compile(level, spec); err != nil {
+			return nil, err
+		}
+	}
+	if t.timestamp, err = compile("timestamp", definition.Timestamp); err != nil {
+		return nil, err
+	}
+	if t.logger, err = compile("logger", definition.Logger); err != nil {
+		return nil, err
+	}
+	if t.stackTrace, err = compile("stacktrace", definition.StackTrace); err != nil {
+		return nil, err
+	}
+	for _, spec := range definition.Sources {
+		c, err := compile("sources", spec)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			t.sources = append(t.sources, c)
+		}
+	}
+	if len(t.sources) == 0 {
+		return nil, fmt.Errorf("invalid theme %s: sources: at least one color is required", name)
+	}
+
+	return t, nil
+}
+
+func mustCompileTheme(name string) *Theme {
+	t, err := CompileTheme(name, nil, true)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// themeLevels are the levels a theme can color
+var themeLevels = map[string]bool{"TRACE": true, "DEBUG": true, "INFO": true, "WARN": true, "ERROR": true}
+
+// mergeTheme returns base with the colors set in override
+func mergeTheme(base config.Theme, override config.Theme) config.Theme {
+	result := base
+	result.Levels = maps.Clone(base.Levels)
+	if result.Levels == nil {
+		result.Levels = map[string]string{}
+	}
+	for level, spec := range override.Levels {
+		result.Levels[strings.ToUpper(level)] = spec
+	}
+	if override.Timestamp != "" {
+		result.Timestamp = override.Timestamp
+	}
+	if override.Logger != "" {
+		result.Logger = override.Logger
+	}
+	if len(override.Sources) > 0 {
+		result.Sources = override.Sources
+	}
+	if override.StackTrace != "" {
+		result.StackTrace = override.StackTrace
+	}
+	return result
+}
+
+// colorNames are the names of the basic colors, in the order of their ANSI codes
+var colorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// styleNames are the text attributes of colors
+var styleNames = map[string]color.Attribute{
+	"bold":      color.Bold,
+	"faint":     color.Faint,
+	"italic":    color.Italic,
+	"underline": color.Underline,
+	"reverse":   color.ReverseVideo,
+}
+
+// parseColor parses a color like "red bold", "hi-black", "bg:blue", "208" or "#ff8700". It returns nil for "" and
+// "none".
+func parseColor(spec string, trueColor bool) (*color.Color, error) {
+	var attributes []color.Attribute
+	for _, word := range strings.Fields(strings.ToLower(spec)) {
+		if word == "none" {
+			continue
+		}
+		if attribute, ok := styleNames[word]; ok {
+			attributes = append(attributes, attribute)
+			continue
+		}
+
+		offset, name := color.FgBlack, word
+		if background, ok := strings.CutPrefix(word, "bg:"); ok {
+			offset, name = color.BgBlack, background
+		}
+		colorAttributes, err := parseColorName(name, offset, trueColor)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, colorAttributes...)
+	}
+
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	return NewColor(attributes...), nil
+}
+
+// parseColorName returns the attributes of a foreground (offset FgBlack) or background (offset BgBlack) color
+func parseColorName(name string, offset color.Attribute, trueColor bool) ([]color.Attribute, error) {
+	// 38 and 48 select extended foreground and background colors
+	extended := offset + 8
+
+	if hex, ok := strings.CutPrefix(name, "#"); ok {
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return nil, fmt.Errorf("invalid color %q (expected #rrggbb)", name)
+		}
+		r, g, b := int(rgb>>16), int(rgb>>8&0xff), int(rgb&0xff)
+		if trueColor {
+			return []color.Attribute{extended, 2, color.Attribute(r), color.Attribute(g), color.Attribute(b)}, nil
+		}
+		return []color.Attribute{extended, 5, color.Attribute(ansi256(r, g, b))}, nil
+	}
+
+	if n, err := strconv.Atoi(name); err == nil {
+		if n < 0 || n > 255 {
+			return nil, fmt.Errorf("invalid color %q (256 colors are numbered 0 to 255)", name)
+		}
+		return []color.Attribute{extended, 5, color.Attribute(n)}, nil
+	}
+
+	bright, base := false, name
+	if rest, ok := strings.CutPrefix(name, "hi-"); ok {
+		bright, base = true, rest
+	}
+	if base == "gray" || base == "grey" {
+		bright, base = true, "black"
+	}
+	index := slices.Index(colorNames, base)
+	if index < 0 {
+		return nil, fmt.Errorf("invalid color %q (allowed: %s, hi-<color>, gray, bold, faint, italic, underline, reverse, 0-255, #rrggbb, bg:<color>)", name, strings.Join(colorNames, ", "))
+	}
+	if bright {
+		return []color.Attribute{offset + 60 + color.Attribute(index)}, nil
+	}
+	return []color.Attribute{offset + color.Attribute(index)}, nil
+}
+
+// ansi256 returns the nearest color of the 256 color palette: of the 6x6x6 color cube or the grayscale ramp
+func ansi256(r int, g int, b int) int {
+	levels := []int{0, 95, 135, 175, 215, 255}
+	nearestLevel := func(v int) int {
+		best := 0
+		for i, level := range levels {
+			if abs(v-level) < abs(v-levels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	cr, cg, cb := nearestLevel(r), nearestLevel(g), nearestLevel(b)
+	cube := 16 + 36*cr + 6*cg + cb
+	cubeDistance := square(r-levels[cr]) + square(g-levels[cg]) + square(b-levels[cb])
+
+	gray := min(max((r+g+b)/3-3, 0)/10, 23)
+	grayLevel := 8 + 10*gray
+	grayDistance := square(r-grayLevel) + square(g-grayLevel) + square(b-grayLevel)
+
+	if grayDistance < cubeDistance {
+		return 232 + gray
+	}
+	return cube
+}
+
+func abs(v int) int {
+	return max(v, -v)
+}
+
+func square(v int) int {
+	return v * v
+}
+
+// NewColor returns a color with the given attributes. Unlike color.New, it only depends on color.NoColor and not on
+// NO_COLOR, which is already taken into account by --color, so that --color=always wins over NO_COLOR.
+func NewColor(attributes ...color.Attribute) *color.Color {
+	return (&color.Color{}).Add(attributes...)
+}
+
+// styled colors s with c, if set
+func styled(c *color.Color, s string) string {
+	if c == nil {
+		return s
+	}
+	return c.Sprint(s)
+}
+
+// styledPadded colors s with c, keeping its trailing padding uncolored
+func styledPadded(c *color.Color, s string) string {
+	text := strings.TrimRight(s, " ")
+	return styled(c, text) + s[len(text):]
+}
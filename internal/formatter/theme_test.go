@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+func TestParseColor(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	tests := []struct {
+		name      string
+		spec      string
+		trueColor bool
+		expected  string
+		errorMsg  string
+	}{
+		{"Basic color with style", "red bold", false, "\x1b[31;1mx\x1b[0;22m", ""},
+		{"Bright color", "hi-black", false, "\x1b[90mx\x1b[0m", ""},
+		{"Gray", "Gray", false, "\x1b[90mx\x1b[0m", ""},
+		{"Background", "black bg:hi-yellow", false, "\x1b[30;103mx\x1b[0;0m", ""},
+		{"256 colors", "208", false, "\x1b[38;5;208mx\x1b[0;25;0m", ""},
+		{"Truecolor", "#ff8700", true, "\x1b[38;2;255;135;0mx\x1b[0;22;0;0;0m", ""},
+		{"Truecolor approximated", "#ff8700", false, "\x1b[38;5;208mx\x1b[0;25;0m", ""},
+		{"Gray approximated", "#808080", false, "\x1b[38;5;244mx\x1b[0;25;0m", ""},
+		{"None", "none", false, "x", ""},
+		{"Empty", "", false, "x", ""},
+		{"Unknown color", "pink", false, "", `invalid color "pink"`},
+		{"256 colors out of range", "256", false, "", `invalid color "256" (256 colors are numbered 0 to 255)`},
+		{"Invalid hex", "#fff", false, "", `invalid color "#fff" (expected #rrggbb)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseColor(tt.spec, tt.trueColor)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if got := styled(c, "x"); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompileTheme(t *testing.T) {
+	userThemes := map[string]config.Theme{
+		"mine":      {Base: "light", Levels: map[string]string{"error": "magenta"}},
+		"badBase":   {Base: "neon"},
+		"badLevel":  {Levels: map[string]string{"FATAL": "red"}},
+		"badColor":  {Logger: "pink"},
+		"noSources": {Base: "light", Sources: []string{"none"}},
+	}
+
+	tests := []struct {
+		name     string
+		theme    string
+		errorMsg string
+	}{
+		{"Default", "", ""},
+		{"Built-in", "solarized", ""},
+		{"User-defined", "mine", ""},
+		{"Unknown theme", "neon", "unknown theme: neon (available: badBase, badColor, badLevel, dark, high-contrast, light, mine, noSources, solarized)"},
+		{"Unknown base theme", "badBase", "invalid theme badBase: unknown base theme neon (allowed: dark, high-contrast, light, solarized)"},
+		{"Unknown level", "badLevel", "invalid theme badLevel: unknown level FATAL (allowed: TRACE, DEBUG, INFO, WARN, ERROR)"},
+		{"Invalid color", "badColor", `invalid theme badColor: logger: invalid color "pink"`},
+		{"No sources", "noSources", "invalid theme noSources: sources: at least one color is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileTheme(tt.theme, userThemes, true)
+			if tt.errorMsg == "" && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if tt.errorMsg != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.errorMsg)) {
+				t.Errorf("Expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestFormat_Theme(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	userThemes := map[string]config.Theme{
+		"mine": {Levels: map[string]string{"ERROR": "magenta"}, Timestamp: "blue", Logger: "green", StackTrace: "faint"},
+	}
+	mine, err := CompileTheme("mine", userThemes, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	UseTheme(mine)
+	defer UseTheme(mustCompileTheme(DefaultTheme))
+
+	msg := &parser.LogMessage{
+		Timestamp:  "2024-05-01T14:00:00.00",
+		Level:      "ERROR",
+		Logger:     "com.acme.Client",
+		Message:    "failed",
+		StackTrace: []string{"at com.acme.Client.call(Client.java:42)"},
+	}
+
+	output := Format(msg, LevelColorizer(msg.Level), &config.Config{})
+	expected := "\x1b[34m2024-05-01T14:00:00.00\x1b[0m \x1b[35m[ERROR]\x1b[0m \x1b[32mcom.acme.Client\x1b[0m" +
+		strings.Repeat(" ", 25) + " : failed\n" +
+		"    \x1b[2mat com.acme.Client.call(Client.java:42)\x1b[22m"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+func TestNewColor_IgnoresNoColorEnv(t *testing.T) {
+	origNoColor := color.NoColor
+	defer func() { color.NoColor = origNoColor }()
+	t.Setenv("NO_COLOR", "1")
+
+	c := NewColor(color.FgRed)
+	color.NoColor = false
+	if got := c.Sprint("x"); got != "\x1b[31mx\x1b[0m" {
+		t.Errorf("Expected colored text with --color=always, got %q", got)
+	}
+	color.NoColor = true
+	if got := c.Sprint("x"); got != "x" {
+		t.Errorf("Expected plain text with --color=never, got %q", got)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package grouper
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// continuationRegex matches the lines Java prints after the first line of an exception.
+// The leading tab is already stripped by the parser.
+var continuationRegex = regexp.MustCompile(`^(at\s+\S+\(.*\)|\.\.\.\s+\d+\s+(more|common frames omitted)|Caused by:\s|Suppressed:\s)`)
+
+// Grouper folds plain text stack trace lines into the preceding message of the same source
+type Grouper struct {
+	Timeout time.Duration
+}
+
+// pendingMessage is a message waiting to be emitted. Open messages may still receive continuation lines.
+type pendingMessage struct {
+	msg      *parser.LogMessage
+	open     bool
+	lastSeen time.Time
+}
+
+func New(timeout time.Duration) *Grouper {
+	return &Grouper{
+		Timeout: timeout,
+	}
+}
+
+// Run consumes messages from in and emits them on the returned channel with continuation lines
+// folded into the StackTrace of the message they belong to. A held back message is closed when
+// the next non-continuation line of the same source arrives, after Timeout without further lines,
+// or when in is closed. Messages are emitted in the order they arrived, so a message waits until
+// all older messages are closed.
+func (g *Grouper) Run(in <-chan *parser.LogMessage) <-chan *parser.LogMessage {
+	out := make(chan *parser.LogMessage)
+
+	go func() {
+		defer close(out)
+
+		var queue []*pendingMessage          // messages not yet emitted, oldest first
+		open := map[string]*pendingMessage{} // open message per source
+
+		closeSource := func(source string) {
+			if p, ok := open[source]; ok {
+				p.open = false
+				delete(open, source)
+			}
+		}
+		release := func() {
+			for len(queue) > 0 && !queue[0].open {
+				out <- queue[0].msg
+				queue[0] = nil
+				queue = queue[1:]
+			}
+		}
+
+		timer := time.NewTimer(g.Timeout)
+		defer timer.Stop()
+
+		for {
+			select {
+			case msg, ok := <-in:
+				if !ok {
+					for source := range open {
+						closeSource(source)
+					}
+					release()
+					return
+				}
+
+				if p, ok := open[msg.Source]; ok && IsContinuation(msg) {
+					p.msg.StackTrace = append(p.msg.StackTrace, stackTraceLine(msg.Message))
+					p.msg.Raw += "\n" + msg.Raw
+					p.lastSeen = time.Now()
+					continue
+				}
+
+				closeSource(msg.Source)
+
+				// Structured logs carry their own stack trace
+				p := &pendingMessage{msg: msg, open: isPlain(msg), lastSeen: time.Now()}
+				if p.open {
+					open[msg.Source] = p
+				}
+				queue = append(queue, p)
+				release()
+
+			case now := <-timer.C:
+				for source, p := range open {
+					if now.Sub(p.lastSeen) >= g.Timeout {
+						closeSource(source)
+					}
+				}
+				release()
+				timer.Reset(g.Timeout)
+			}
+		}
+	}()
+
+	return out
+}
+
+// IsContinuation reports whether msg is a follow-up line of a plain text stack trace
+func IsContinuation(msg *parser.LogMessage) bool {
+	return isPlain(msg) && continuationRegex.MatchString(msg.Message)
+}
+
+// isPlain reports whether msg was not parsed from a structured JSON log
+func isPlain(msg *parser.LogMessage) bool {
+	return msg.HasParseError || msg.Source == ""
+}
+
+// stackTraceLine restores the indentation Java uses for frames, so folded traces look like structured ones
+func stackTraceLine(line string) string {
+	if line[0] == 'a' || line[0] == '.' {
+		return "\t" + line
+	}
+	return line
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package grouper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// helper to run the given CF log lines through a grouper and collect the result
+func group(t *testing.T, timeout time.Duration, lines ...string) []*parser.LogMessage {
+	t.Helper()
+
+	in := make(chan *parser.LogMessage)
+	go func() {
+		defer close(in)
+		for _, line := range lines {
+			msg, ok := parser.ParseLine(line)
+			if !ok {
+				t.Errorf("Expected line to be parsed: %s", line)
+				continue
+			}
+			in <- msg
+		}
+	}()
+
+	var result []*parser.LogMessage
+	for msg := range New(timeout).Run(in) {
+		result = append(result, msg)
+	}
+	return result
+}
+
+func TestGrouper_FoldsStackTrace(t *testing.T) {
+	result := group(t, time.Minute,
+		"2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] ERR java.lang.IllegalStateException: boom",
+		"2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] ERR \tat com.foo.Service.run(Service.java:42)",
+		"2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] ERR \tat java.base/java.lang.Thread.run(Thread.java:833)",
+		"2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] ERR Caused by: java.io.IOException: closed",
+		"2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] ERR \t... 12 more",
+		"2024-01-20T09:37:59.01+0100 [APP/PROC/WEB/0] OUT Next message",
+	)
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(result))
+	}
+
+	expected := []string{
+		"\tat com.foo.Service.run(Service.java:42)",
+		"\tat java.base/java.lang.Thread.run(Thread.java:833)",
+		"Caused by: java.io.IOException: closed",
+		"\t... 12 more",
+	}
+	if len(result[0].StackTrace) != len(expected) {
+		t.Fatalf("Expected %d stacktrace lines, got %v", len(expected), result[0].StackTrace)
+	}
+	for i, line := range expected {
+		if result[0].StackTrace[i] != line {
+			t.Errorf("Expected stacktrace line %d to be %q, got %q", i, line, result[0].StackTrace[i])
+		}
+	}
+
+	if result[1].Message != "Next message" {
+		t.Errorf("Expected second message to be 'Next message', got %s", result[1].Message)
+	}
+}
+
+func TestGrouper_KeepsSourcesApart(t *testing.T) {
+	result := group(t, time.Minute,
+		"2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] ERR java.lang.IllegalStateException: boom",
+		"2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/1] ERR java.lang.NullPointerException",
+		"2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] ERR \tat com.foo.Service.run(Service.java:42)",
+		"2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/1] ERR \tat com.foo.Other.run(Other.java:7)",
+	)
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(result))
+	}
+	for _, msg := range result {
+		if len(msg.StackTrace) != 1 {
+			t.Errorf("Expected 1 stacktrace line for %s, got %v", msg.Source, msg.StackTrace)
+		}
+	}
+	if result[0].StackTrace[0] != "\tat com.foo.Service.run(Service.java:42)" {
+		t.Errorf("Unexpected stacktrace for WEB/0: %v", result[0].StackTrace)
+	}
+	if result[1].StackTrace[0] != "\tat com.foo.Other.run(Other.java:7)" {
+		t.Errorf("Unexpected stacktrace for WEB/1: %v", result[1].StackTrace)
+	}
+}
+
+func TestGrouper_StructuredLogsPassThrough(t *testing.T) {
+	result := group(t, time.Minute,
+		`2023-04-30T08:39:16.76+0200 [APP/PROC/WEB/0] OUT { "written_at":"2023-04-30T06:39:16.766Z","level":"INFO","logger":"com.foo.bar","msg":"first" }`,
+		"2023-04-30T08:39:16.76+0200 [APP/PROC/WEB/0] ERR \tat com.foo.Service.run(Service.java:42)",
+	)
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(result))
+	}
+	if len(result[0].StackTrace) != 0 {
+		t.Errorf("Expected no stacktrace on structured message, got %v", result[0].StackTrace)
+	}
+}
+
+func TestGrouper_KeepsOrderAcrossSources(t *testing.T) {
+	result := group(t, time.Minute,
+		"2024-01-20T09:37:58.10+0100 [CELL/0] OUT Cell health check passed",
+		`2024-01-20T09:37:58.20+0100 [APP/PROC/WEB/0] OUT { "written_at":"2024-01-20T08:37:58.200Z","level":"INFO","logger":"com.foo.bar","msg":"second" }`,
+		"2024-01-20T09:37:58.30+0100 [APP/PROC/WEB/1] ERR java.lang.IllegalStateException: boom",
+		"2024-01-20T09:37:58.30+0100 [APP/PROC/WEB/1] ERR \tat com.foo.Service.run(Service.java:42)",
+		`2024-01-20T09:37:58.40+0100 [APP/PROC/WEB/0] OUT { "written_at":"2024-01-20T08:37:58.400Z","level":"INFO","logger":"com.foo.bar","msg":"fourth" }`,
+	)
+
+	expected := []string{"Cell health check passed", "second", "java.lang.IllegalStateException: boom", "fourth"}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d", len(expected), len(result))
+	}
+	for i, message := range expected {
+		if result[i].Message != message {
+			t.Errorf("Expected message %d to be %q, got %q", i, message, result[i].Message)
+		}
+	}
+	if len(result[2].StackTrace) != 1 {
+		t.Errorf("Expected 1 stacktrace line for WEB/1, got %v", result[2].StackTrace)
+	}
+}
+
+func TestGrouper_FlushesAfterTimeout(t *testing.T) {
+	in := make(chan *parser.LogMessage)
+	out := New(10 * time.Millisecond).Run(in)
+	defer close(in)
+
+	msg, _ := parser.ParseLine("2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] ERR java.lang.IllegalStateException: boom")
+	in <- msg
+
+	select {
+	case got := <-out:
+		if got != msg {
+			t.Errorf("Expected held back message to be flushed, got %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected message to be flushed after timeout")
+	}
+}
+
+func TestIsContinuation(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bool
+	}{
+		{"at com.foo.Service.run(Service.java:42)", true},
+		{"at java.base/java.lang.Thread.run(Unknown Source)", true},
+		{"Caused by: java.io.IOException: closed", true},
+		{"Suppressed: java.io.IOException: closed", true},
+		{"... 12 more", true},
+		{"... 3 common frames omitted", true},
+		{"java.lang.IllegalStateException: boom", false},
+		{"at the end of the day", false},
+		{"Server started", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			got := IsContinuation(&parser.LogMessage{Message: tt.message, HasParseError: true})
+			if got != tt.expected {
+				t.Errorf("Expected continuation = %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Stdin is the input name that refers to standard input
+const Stdin = "-"
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Open opens the named file for reading, or standard input for "-". Gzip and zstd compressed content
+// is detected by its magic bytes and decompressed transparently, independent of the file extension.
+func Open(name string) (io.ReadCloser, error) {
+	var file io.ReadCloser = os.Stdin
+	if name != Stdin {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		file = f
+	}
+
+	r, err := decompress(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// decompress wraps file into a decompressing reader if its content starts with a known magic number
+func decompress(file io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(file)
+
+	// A short or empty input is simply not compressed
+	head, _ := buffered.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return &readCloser{Reader: gz, close: func() error {
+			_ = gz.Close()
+			return file.Close()
+		}}, nil
+
+	case bytes.HasPrefix(head, zstdMagic):
+		zr, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return &readCloser{Reader: zr, close: func() error {
+			zr.Close()
+			return file.Close()
+		}}, nil
+
+	default:
+		return &readCloser{Reader: buffered, close: file.Close}, nil
+	}
+}
+
+// readCloser combines a (decompressing) reader with the close function of the underlying file
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r *readCloser) Close() error {
+	return r.close()
+}
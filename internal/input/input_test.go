@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package input
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const content = "2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] OUT first\n2024-01-20T09:37:59.01+0100 [APP/PROC/WEB/0] OUT second\n"
+
+// helper to write a file into a temporary directory
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	return path
+}
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("Failed to gzip: %v", err)
+	}
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func zstdCompressed(t *testing.T, data string) []byte {
+	t.Helper()
+
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create zstd writer: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+	return w.EncodeAll([]byte(data), nil)
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     []byte
+	}{
+		{"Plain file", "app.log", []byte(content)},
+		{"Gzip file", "app.log.gz", gzipped(t, content)},
+		{"Gzip file without extension", "app.log", gzipped(t, content)},
+		{"Zstd file", "app.log.zst", zstdCompressed(t, content)},
+		{"Empty file", "empty.log", []byte{}},
+		{"Single byte file", "short.log", []byte("x")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.fileName, tt.data)
+
+			r, err := Open(path)
+			if err != nil {
+				t.Fatalf("Expected file to be opened, got: %v", err)
+			}
+			defer func() { _ = r.Close() }()
+
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Expected file to be read, got: %v", err)
+			}
+
+			expected := string(tt.data)
+			if tt.fileName != "empty.log" && tt.fileName != "short.log" {
+				expected = content
+			}
+			if string(got) != expected {
+				t.Errorf("Expected content %q, got %q", expected, string(got))
+			}
+		})
+	}
+}
+
+func TestOpen_MissingFile(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing.log"))
+	if err == nil {
+		t.Fatal("Expected error for missing file")
+	}
+}
+
+func TestOpen_CorruptGzip(t *testing.T) {
+	path := writeFile(t, "corrupt.log.gz", []byte{0x1f, 0x8b, 0x00})
+
+	_, err := Open(path)
+	if err == nil {
+		t.Fatal("Expected error for corrupt gzip header")
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package merge
+
+import (
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// Merger combines several message streams into one, ordered by timestamp
+type Merger struct {
+	Window time.Duration
+}
+
+type event struct {
+	input int
+	msg   *parser.LogMessage
+	ok    bool
+}
+
+type head struct {
+	msg     *parser.LogMessage
+	arrived time.Time
+}
+
+func New(window time.Duration) *Merger {
+	return &Merger{
+		Window: window,
+	}
+}
+
+// Run performs a k-way merge of the inputs, which are expected to be ordered by timestamp each.
+// The oldest message is emitted once every open input has a message waiting. With a Window > 0,
+// it is also emitted when it waited for Window without the other inputs catching up, so a quiet
+// live stream doesn't hold back the others.
+func (m *Merger) Run(inputs []<-chan *parser.LogMessage) <-chan *parser.LogMessage {
+	out := make(chan *parser.LogMessage)
+
+	events := make(chan event)
+	resume := make([]chan struct{}, len(inputs))
+	for i, in := range inputs {
+		resume[i] = make(chan struct{}, 1)
+		go func() {
+			for msg := range in {
+				events <- event{input: i, msg: msg, ok: true}
+				// Only one message per input is needed, wait until it was emitted
+				<-resume[i]
+			}
+			events <- event{input: i}
+		}()
+	}
+
+	go func() {
+		defer close(out)
+
+		heads := make([]*head, len(inputs))
+		closed := make([]bool, len(inputs))
+		open := len(inputs)
+
+		for {
+			oldest := -1
+			for {
+				oldest = oldestHead(heads)
+				if oldest < 0 || !m.canEmit(heads, closed, oldest) {
+					break
+				}
+				out <- heads[oldest].msg
+				heads[oldest] = nil
+				resume[oldest] <- struct{}{}
+			}
+
+			if open == 0 && oldest < 0 {
+				return
+			}
+
+			var timeout <-chan time.Time
+			if m.Window > 0 && oldest >= 0 {
+				timeout = time.After(m.Window - time.Since(heads[oldest].arrived))
+			}
+
+			select {
+			case ev := <-events:
+				if !ev.ok {
+					closed[ev.input] = true
+					open--
+					continue
+				}
+				heads[ev.input] = &head{msg: ev.msg, arrived: time.Now()}
+			case <-timeout:
+			}
+		}
+	}()
+
+	return out
+}
+
+// canEmit checks if the head of input i may be emitted without breaking the order
+func (m *Merger) canEmit(heads []*head, closed []bool, i int) bool {
+	if m.Window > 0 && time.Since(heads[i].arrived) >= m.Window {
+		return true
+	}
+
+	for j := range heads {
+		if heads[j] == nil && !closed[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// oldestHead returns the index of the waiting message with the lowest timestamp, or -1 if none is waiting
+func oldestHead(heads []*head) int {
+	oldest := -1
+	for i, h := range heads {
+		if h == nil {
+			continue
+		}
+		if oldest < 0 || h.msg.Time.Before(heads[oldest].msg.Time) {
+			oldest = i
+		}
+	}
+	return oldest
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package merge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// helper to build an input stream of messages with the given timestamps
+func stream(label string, timestamps ...string) <-chan *parser.LogMessage {
+	ch := make(chan *parser.LogMessage)
+	go func() {
+		defer close(ch)
+		for _, ts := range timestamps {
+			parsed, _ := time.Parse("2006-01-02T15:04:05.999-0700", ts)
+			ch <- &parser.LogMessage{Timestamp: ts, Time: parsed, Label: label}
+		}
+	}()
+	return ch
+}
+
+func TestMerger_OrdersByTimestamp(t *testing.T) {
+	inputs := []<-chan *parser.LogMessage{
+		stream("a", "2024-01-20T09:00:00.10+0000", "2024-01-20T09:00:00.40+0000", "2024-01-20T09:00:00.50+0000"),
+		stream("b", "2024-01-20T09:00:00.20+0000", "2024-01-20T09:00:00.30+0000"),
+		stream("c"),
+		stream("d", "2024-01-20T09:00:00.05+0000", "2024-01-20T09:00:00.60+0000"),
+	}
+
+	var got []string
+	for msg := range New(0).Run(inputs) {
+		got = append(got, msg.Label+" "+msg.Timestamp[20:22])
+	}
+
+	expected := []string{"d 05", "a 10", "b 20", "b 30", "a 40", "a 50", "d 60"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, got)
+			break
+		}
+	}
+}
+
+func TestMerger_ComparesAcrossTimezones(t *testing.T) {
+	inputs := []<-chan *parser.LogMessage{
+		stream("a", "2024-01-20T09:00:00.10+0100"),
+		stream("b", "2024-01-20T08:30:00.00+0000"),
+	}
+
+	first := <-New(0).Run(inputs)
+	if first.Label != "a" {
+		t.Errorf("Expected 08:00:00.10 UTC of input a before 08:30 UTC of input b, got %s first", first.Label)
+	}
+}
+
+func TestMerger_WaitsForAllInputsWithoutWindow(t *testing.T) {
+	quiet := make(chan *parser.LogMessage)
+	out := New(0).Run([]<-chan *parser.LogMessage{stream("a", "2024-01-20T09:00:00.10+0000"), quiet})
+
+	select {
+	case msg := <-out:
+		t.Fatalf("Expected no message while an input is quiet, got %v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(quiet)
+	if msg := <-out; msg == nil || msg.Label != "a" {
+		t.Errorf("Expected message of input a after the quiet input closed, got %v", msg)
+	}
+}
+
+func TestMerger_EmitsAfterWindow(t *testing.T) {
+	quiet := make(chan *parser.LogMessage)
+	defer close(quiet)
+	out := New(20 * time.Millisecond).Run([]<-chan *parser.LogMessage{stream("a", "2024-01-20T09:00:00.10+0000"), quiet})
+
+	select {
+	case msg := <-out:
+		if msg.Label != "a" {
+			t.Errorf("Expected message of input a, got %v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected message to be emitted after the reorder window")
+	}
+}
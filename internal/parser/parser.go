@@ -7,12 +7,16 @@ package parser
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type LogMessage struct {
-	Timestamp     string
+	Timestamp     string    // as reported by the CF CLI, without offset
+	Time          time.Time // written_at of JSON logs, otherwise the CF timestamp; zero if unknown
 	Source        string
 	Direction     string
 	Level         string
@@ -21,34 +25,70 @@ type LogMessage struct {
 	StackTrace    []string
 	Raw           string
 	HasParseError bool
-}
+	Label         string        // name of the input the message was read from, if several inputs are combined
+	Repeated      int           // number of collapsed duplicates including this message, 0 if not deduplicated
+	RepeatedFor   time.Duration // time between the first and the last duplicate
+
+	// Fields of the SAP cf-java-logging-support JSON schema (empty for plain text logs)
+	Type              string // "log" for application logs, "request" for request logs
+	WrittenAt         string
+	Thread            string
+	CorrelationID     string
+	RequestID         string
+	TenantID          string
+	TenantSubdomain   string
+	ComponentName     string
+	ComponentInstance string
+	Request           string
+	Method            string
+	RemoteIP          string
+	ResponseStatus    int
+	ResponseTimeMs    float64
+	ResponseSizeB     int
+	CustomFields      map[string]string
 
-var cfPrefixRegex = regexp.MustCompile(`^\s*(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{2,})\+\d{4}\s+\[([^]]+)]\s+(OUT|ERR)\s+`)
+	// Fields of Gorouter access logs (RTR), which also fill Method, Request, RemoteIP, ResponseStatus,
+	// ResponseTimeMs, ResponseSizeB and CorrelationID
+	Host           string
+	UserAgent      string
+	RequestSizeB   int
+	GorouterTimeMs float64
+	AppIndex       string
+	TraceID        string
+	VcapRequestID  string
 
-type structuredLog struct {
-	WrittenAt  string   `json:"written_at"`
-	Level      string   `json:"level"`
-	Logger     string   `json:"logger"`
-	Msg        string   `json:"msg"`
-	StackTrace []string `json:"stacktrace"`
+	Fields map[string]any // all fields of the JSON or access log, including the ones above
 }
 
+// cfTimeLayout is the layout of the CF timestamp including its offset, e.g. "2023-04-30T08:39:15.71+0200"
+const cfTimeLayout = "2006-01-02T15:04:05.999999999-0700"
+
+var cfPrefixRegex = regexp.MustCompile(`^\s*(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{2,})([+-]\d{4})\s+\[([^]]+)]\s+(OUT|ERR)\s+`)
+
+// accessLogRegex matches the Gorouter access log format:
+// <host> - [<time>] "<method> <path> <protocol>" <status> <bytes received> <bytes sent> "<referer>" "<user agent>" "<remote addr>" "<backend addr>" key:"value" ...
+var accessLogRegex = regexp.MustCompile(`^(\S+) - \[([^]]+)] "(\S+) (\S+) ([^"]*)" (\d{3}) (\d+) (\d+) "([^"]*)" "([^"]*)" "([^"]*)" "([^"]*)"(.*)$`)
+
+// accessLogFieldRegex matches the key:"value" and key:value pairs at the end of an access log line
+var accessLogFieldRegex = regexp.MustCompile(`(\w+):(?:"([^"]*)"|(\S+))`)
+
 // ParseLine parses one line of CF log
 func ParseLine(line string) (*LogMessage, bool) {
 	matches := cfPrefixRegex.FindStringSubmatch(line)
 	loc := cfPrefixRegex.FindStringIndex(line)
 
-	if matches == nil || loc == nil || len(matches) < 4 {
+	if matches == nil || loc == nil || len(matches) < 5 {
 		return parseFallbackLine(line)
 	}
 
 	timestamp := matches[1]
-	source := matches[2]
-	direction := matches[3]
+	source := matches[3]
+	direction := matches[4]
 	rest := strings.TrimSpace(line[loc[1]:])
 
 	msg := &LogMessage{
 		Timestamp:     timestamp,
+		Time:          parseTime(cfTimeLayout, timestamp+matches[2]),
 		Source:        source,
 		Level:         "-----", // Default will be overwritten if available
 		Direction:     direction,
@@ -56,30 +96,303 @@ func ParseLine(line string) (*LogMessage, bool) {
 		HasParseError: false,
 	}
 
+	if strings.HasPrefix(source, "RTR") && parseAccessLog(msg, rest) {
+		return msg, true
+	}
+
 	// Try to parse the remaining content as JSON
 	rest = strings.ReplaceAll(rest, "\t", "\\t")
 	rest = strings.ReplaceAll(rest, "\n", "\\n")
 
-	var structured structuredLog
+	var fields map[string]any
 	decoder := json.NewDecoder(strings.NewReader(rest))
+	decoder.UseNumber()
 
-	err := decoder.Decode(&structured)
-	if err != nil {
+	err := decoder.Decode(&fields)
+	if err != nil || fields == nil {
 		// Not a valid JSON log, fallback to plain message
 		msg.HasParseError = true
 		msg.Message = rest
 		return msg, true
 	}
 
-	msg.Level = structured.Level
+	flattenContext(fields)
+	msg.Level = stringField(fields, "level")
+
+	msg.Logger = stringField(fields, "logger")
+	msg.Message = stringField(fields, "msg")
+	msg.StackTrace = stringSliceField(fields, "stacktrace")
 
-	msg.Logger = structured.Logger
-	msg.Message = structured.Msg
-	msg.StackTrace = structured.StackTrace
+	msg.Type = stringField(fields, "type")
+	msg.WrittenAt = stringField(fields, "written_at")
+	if writtenAt := parseTime(time.RFC3339Nano, msg.WrittenAt); !writtenAt.IsZero() {
+		msg.Time = writtenAt
+	}
+	msg.Thread = stringField(fields, "thread")
+	msg.CorrelationID = stringField(fields, "correlation_id")
+	msg.RequestID = stringField(fields, "request_id")
+	msg.TenantID = stringField(fields, "tenant_id")
+	msg.TenantSubdomain = stringField(fields, "tenant_subdomain")
+	msg.ComponentName = stringField(fields, "component_name")
+	msg.ComponentInstance = stringField(fields, "component_instance")
+	msg.Request = stringField(fields, "request")
+	msg.Method = stringField(fields, "method")
+	msg.RemoteIP = stringField(fields, "remote_ip")
+	msg.ResponseStatus = int(numberField(fields, "response_status"))
+	msg.ResponseTimeMs = numberField(fields, "response_time_ms")
+	msg.ResponseSizeB = int(numberField(fields, "response_size_b"))
+	msg.CustomFields = customFields(fields)
+	msg.Fields = fields
 
 	return msg, true
 }
 
+// parseAccessLog fills msg from a Gorouter access log line and reports whether line had that format.
+// The original line is kept as message.
+func parseAccessLog(msg *LogMessage, line string) bool {
+	m := accessLogRegex.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+
+	fields := map[string]any{
+		"host":           m[1],
+		"request_time":   m[2],
+		"method":         m[3],
+		"path":           m[4],
+		"protocol":       m[5],
+		"status":         m[6],
+		"bytes_received": m[7],
+		"bytes_sent":     m[8],
+		"referer":        m[9],
+		"user_agent":     m[10],
+		"remote_addr":    m[11],
+		"backend_addr":   m[12],
+	}
+	for _, kv := range accessLogFieldRegex.FindAllStringSubmatch(m[13], -1) {
+		if kv[3] != "" {
+			fields[kv[1]] = kv[3]
+		} else {
+			fields[kv[1]] = kv[2]
+		}
+	}
+
+	msg.Type = "router"
+	msg.Message = line
+	msg.Host = m[1]
+	msg.Method = m[3]
+	msg.Request = m[4]
+	msg.ResponseStatus, _ = strconv.Atoi(m[6])
+	msg.RequestSizeB, _ = strconv.Atoi(m[7])
+	msg.ResponseSizeB, _ = strconv.Atoi(m[8])
+	msg.UserAgent = m[10]
+	msg.RemoteIP, _, _ = strings.Cut(m[11], ":")
+	msg.ResponseTimeMs = numberField(fields, "response_time") * 1000
+	msg.GorouterTimeMs = numberField(fields, "gorouter_time") * 1000
+	msg.AppIndex = dashToEmpty(stringField(fields, "app_index"))
+	msg.TraceID = dashToEmpty(stringField(fields, "x_b3_traceid"))
+	msg.VcapRequestID = dashToEmpty(stringField(fields, "vcap_request_id"))
+	msg.CorrelationID = dashToEmpty(stringField(fields, "x_correlationid"))
+
+	// Make the normalised names available for lookups as well
+	fields["request"] = msg.Request
+	fields["response_status"] = m[6]
+	fields["response_time_ms"] = strconv.FormatFloat(msg.ResponseTimeMs, 'f', -1, 64)
+	fields["response_size_b"] = m[8]
+	fields["remote_ip"] = msg.RemoteIP
+	fields["correlation_id"] = msg.CorrelationID
+	msg.Fields = fields
+
+	return true
+}
+
+// parseTime parses value with the given layout, returning the zero time if it doesn't match
+func parseTime(layout string, value string) time.Time {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// dashToEmpty maps the "-" Gorouter uses for missing values to an empty string
+func dashToEmpty(value string) string {
+	if value == "-" {
+		return ""
+	}
+	return value
+}
+
+// stringField returns the value of key as string, formatting non-string values
+func stringField(fields map[string]any, key string) string {
+	switch v := fields[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// stringSliceField returns the value of key as string slice, e.g. for stack traces
+func stringSliceField(fields map[string]any, key string) []string {
+	values, ok := fields[key].([]any)
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		} else {
+			result = append(result, fmt.Sprint(v))
+		}
+	}
+	return result
+}
+
+// numberField returns the value of key as float64. Numbers sent as strings (e.g. "200") are accepted as well.
+func numberField(fields map[string]any, key string) float64 {
+	var raw string
+	switch v := fields[key].(type) {
+	case json.Number:
+		raw = v.String()
+	case string:
+		raw = v
+	default:
+		return 0
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// flattenContext moves the fields of the "#ctx" section, where the logging library puts context fields like
+// correlation_id and tenant_id, to the top level. Fields of the same name at the top level take precedence.
+func flattenContext(fields map[string]any) {
+	ctx, ok := fields["#ctx"].(map[string]any)
+	if !ok {
+		return
+	}
+
+	delete(fields, "#ctx")
+	for k, v := range ctx {
+		if _, ok := fields[k]; !ok {
+			fields[k] = v
+		}
+	}
+}
+
+// customFields collects custom fields from the "#cf" section ({"string":[{"l":"key","v":"value"}]})
+// and from the older "custom_fields" object
+func customFields(fields map[string]any) map[string]string {
+	result := map[string]string{}
+
+	if legacy, ok := fields["custom_fields"].(map[string]any); ok {
+		for k := range legacy {
+			result[k] = stringField(legacy, k)
+		}
+	}
+
+	if cf, ok := fields["#cf"].(map[string]any); ok {
+		entries, _ := cf["string"].([]any)
+		for _, entry := range entries {
+			e, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			if key := stringField(e, "l"); key != "" {
+				result[key] = stringField(e, "v")
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// Field returns the value of the named field as string. Besides the struct fields (using their JSON names,
+// e.g. "correlation_id"), custom fields and any other field of a JSON log can be looked up.
+func (m *LogMessage) Field(name string) (string, bool) {
+	switch name {
+	case "timestamp":
+		return m.Timestamp, true
+	case "time":
+		if m.Time.IsZero() {
+			return "", true
+		}
+		return m.Time.Format(time.RFC3339Nano), true
+	case "source":
+		return m.Source, true
+	case "source_type":
+		return m.SourceType(), true
+	case "instance":
+		return m.Instance(), true
+	case "direction":
+		return m.Direction, true
+	case "level":
+		return m.Level, true
+	case "logger":
+		return m.Logger, true
+	case "msg", "message":
+		return m.Message, true
+	case "stacktrace":
+		return strings.Join(m.StackTrace, "\n"), true
+	case "raw":
+		return m.Raw, true
+	}
+
+	if m.Fields == nil {
+		return "", false
+	}
+
+	if v, ok := m.CustomFields[name]; ok {
+		return v, true
+	}
+	if _, ok := m.Fields[name]; ok {
+		return stringField(m.Fields, name), true
+	}
+	return "", false
+}
+
+// SourceType returns the type of the source, e.g. "APP" for "APP/PROC/WEB/2" or "RTR" for "RTR/0"
+func (m *LogMessage) SourceType() string {
+	sourceType, _, _ := strings.Cut(m.Source, "/")
+	return sourceType
+}
+
+// Instance returns the instance index of the source, e.g. "2" for "APP/PROC/WEB/2", or "" if there is none
+func (m *LogMessage) Instance() string {
+	i := strings.LastIndex(m.Source, "/")
+	if i < 0 {
+		return ""
+	}
+	if _, err := strconv.Atoi(m.Source[i+1:]); err != nil {
+		return ""
+	}
+	return m.Source[i+1:]
+}
+
+// ShortSource returns the abbreviated source: the process type and instance for apps (e.g. "WEB/2" for
+// "APP/PROC/WEB/2", "TASK/migrate" for "APP/TASK/migrate/0") and the source type for the platform (e.g. "RTR")
+func (m *LogMessage) ShortSource() string {
+	parts := strings.Split(m.Source, "/")
+	switch {
+	case parts[0] == "APP" && len(parts) == 4 && parts[1] == "PROC":
+		return parts[2] + "/" + parts[3]
+	case parts[0] == "APP" && len(parts) >= 3:
+		return parts[1] + "/" + parts[2]
+	}
+	return parts[0]
+}
+
 // parseFallbackLine handles lines that don't match expected format
 func parseFallbackLine(line string) (*LogMessage, bool) {
 	trimmed := strings.TrimSpace(line)
@@ -8,6 +8,7 @@ package parser
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseLine_JSONWithStackTrace(t *testing.T) {
@@ -87,3 +88,258 @@ func TestParseLine_Unstructured(t *testing.T) {
 		t.Errorf("Expected message to be 'test', got %s", msg.Message)
 	}
 }
+
+func TestParseLine_ApplicationLogFields(t *testing.T) {
+	input := `2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/1] OUT {"written_at":"2024-01-20T08:37:58.991Z","written_ts":1705739878991000000,"tenant_id":"8f3a1c2e","tenant_subdomain":"acme","component_type":"application","component_id":"5b6c","component_name":"orders-srv","component_instance":"1","space_name":"dev","organization_name":"acme-org","correlation_id":"d2b5f4a0-8c1e-4c57-a3ab-6a3c0e8f2d11","type":"log","logger":"com.acme.orders.OrderService","thread":"http-nio-8080-exec-3","level":"WARN","categories":[],"msg":"Order 42 is late","#cf":{"string":[{"l":"order_id","v":"42","i":0},{"l":"region","v":"eu10","i":1}]}}`
+
+	msg, ok := ParseLine(input)
+	if !ok {
+		t.Fatal("Expected log line to be parsed")
+	}
+	if msg.HasParseError {
+		t.Fatal("Expected JSON to be parsed without error")
+	}
+
+	checks := map[string][2]string{
+		"Type":              {msg.Type, "log"},
+		"WrittenAt":         {msg.WrittenAt, "2024-01-20T08:37:58.991Z"},
+		"Thread":            {msg.Thread, "http-nio-8080-exec-3"},
+		"CorrelationID":     {msg.CorrelationID, "d2b5f4a0-8c1e-4c57-a3ab-6a3c0e8f2d11"},
+		"TenantID":          {msg.TenantID, "8f3a1c2e"},
+		"TenantSubdomain":   {msg.TenantSubdomain, "acme"},
+		"ComponentName":     {msg.ComponentName, "orders-srv"},
+		"ComponentInstance": {msg.ComponentInstance, "1"},
+		"Level":             {msg.Level, "WARN"},
+		"Message":           {msg.Message, "Order 42 is late"},
+	}
+	for field, values := range checks {
+		if values[0] != values[1] {
+			t.Errorf("Expected %s to be %q, got %q", field, values[1], values[0])
+		}
+	}
+
+	if msg.CustomFields["order_id"] != "42" || msg.CustomFields["region"] != "eu10" {
+		t.Errorf("Unexpected custom fields: %v", msg.CustomFields)
+	}
+	if msg.Fields["space_name"] != "dev" {
+		t.Errorf("Expected generic field space_name to be 'dev', got %v", msg.Fields["space_name"])
+	}
+}
+
+func TestParseLine_ContextSection(t *testing.T) {
+	input := `2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/1] OUT {"written_at":"2024-01-20T08:37:58.991Z","type":"log","logger":"com.acme.orders.OrderService","thread":"main","level":"ERROR","msg":"Payment failed","#ctx":{"correlation_id":"d2b5f4a0-8c1e-4c57-a3ab-6a3c0e8f2d11","tenant_id":"8f3a1c2e","tenant_subdomain":"acme","request_id":"r-17","thread":"ignored","trace_id":"4bf92f3577b34da6"}}`
+
+	msg, ok := ParseLine(input)
+	if !ok || msg.HasParseError {
+		t.Fatal("Expected JSON log line to be parsed")
+	}
+
+	checks := map[string][2]string{
+		"CorrelationID":   {msg.CorrelationID, "d2b5f4a0-8c1e-4c57-a3ab-6a3c0e8f2d11"},
+		"TenantID":        {msg.TenantID, "8f3a1c2e"},
+		"TenantSubdomain": {msg.TenantSubdomain, "acme"},
+		"RequestID":       {msg.RequestID, "r-17"},
+		"Thread":          {msg.Thread, "main"},
+	}
+	for field, values := range checks {
+		if values[0] != values[1] {
+			t.Errorf("Expected %s to be %q, got %q", field, values[1], values[0])
+		}
+	}
+
+	if v, ok := msg.Field("trace_id"); !ok || v != "4bf92f3577b34da6" {
+		t.Errorf("Expected context field trace_id to be looked up, got %q (%v)", v, ok)
+	}
+	if _, ok := msg.Fields["#ctx"]; ok {
+		t.Error("Expected #ctx section to be flattened")
+	}
+}
+
+func TestParseLine_RequestLogFields(t *testing.T) {
+	input := `2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] OUT {"written_at":"2024-01-20T08:37:58.995Z","written_ts":1705739878995000000,"component_name":"orders-srv","correlation_id":"d2b5f4a0-8c1e-4c57-a3ab-6a3c0e8f2d11","type":"request","logger":"com.sap.hcp.cf.logging.servlet.filter.RequestLogger","thread":"http-nio-8080-exec-3","level":"INFO","categories":[],"request":"/api/orders?id=42","request_received_at":"2024-01-20T08:37:58.980Z","response_sent_at":"2024-01-20T08:37:58.995Z","response_time_ms":15.237,"protocol":"HTTP/1.1","method":"GET","remote_ip":"10.0.72.9","remote_host":"redacted","remote_port":"redacted","response_status":404,"response_size_b":123,"request_size_b":-1,"direction":"IN"}`
+
+	msg, ok := ParseLine(input)
+	if !ok {
+		t.Fatal("Expected log line to be parsed")
+	}
+	if msg.Type != "request" {
+		t.Errorf("Expected type request, got %s", msg.Type)
+	}
+	if msg.Method != "GET" || msg.Request != "/api/orders?id=42" {
+		t.Errorf("Unexpected request: %s %s", msg.Method, msg.Request)
+	}
+	if msg.ResponseStatus != 404 {
+		t.Errorf("Expected response status 404, got %d", msg.ResponseStatus)
+	}
+	if msg.ResponseTimeMs != 15.237 {
+		t.Errorf("Expected response time 15.237, got %f", msg.ResponseTimeMs)
+	}
+	if msg.ResponseSizeB != 123 {
+		t.Errorf("Expected response size 123, got %d", msg.ResponseSizeB)
+	}
+	if msg.RemoteIP != "10.0.72.9" {
+		t.Errorf("Expected remote IP 10.0.72.9, got %s", msg.RemoteIP)
+	}
+	if msg.Message != "" {
+		t.Errorf("Expected no message, got %s", msg.Message)
+	}
+}
+
+func TestParseLine_LegacyCustomFieldsAndStringNumbers(t *testing.T) {
+	input := `2024-01-20T09:37:58.99+0100 [APP/PROC/WEB/0] OUT {"level":"INFO","msg":"done","response_status":"201","custom_fields":{"job":"import","count":3}}`
+
+	msg, ok := ParseLine(input)
+	if !ok {
+		t.Fatal("Expected log line to be parsed")
+	}
+	if msg.ResponseStatus != 201 {
+		t.Errorf("Expected response status 201, got %d", msg.ResponseStatus)
+	}
+	if msg.CustomFields["job"] != "import" || msg.CustomFields["count"] != "3" {
+		t.Errorf("Unexpected custom fields: %v", msg.CustomFields)
+	}
+}
+
+func TestParseLine_RouterAccessLogFields(t *testing.T) {
+	input := `   2024-01-20T09:37:58.99+0100 [RTR/1] OUT orders.cfapps.eu10.hana.ondemand.com - [2024-01-20T08:37:58.958163627Z] "POST /api/orders?id=42 HTTP/1.1" 503 2048 67 "-" "Mozilla/5.0 (X11; Linux x86_64)" "10.0.72.9:47230" "10.0.137.5:61002" x_forwarded_for:"203.0.113.7, 10.0.72.9" x_forwarded_proto:"https" vcap_request_id:"5c1a8f0e-2b3d-4e7f-9a10-bb2c3d4e5f60" response_time:0.031457 gorouter_time:0.000218 app_id:"2d1e3f40" app_index:"2" instance_id:"7a8b9c0d" x_cf_routererror:"-" x_correlationid:"d2b5f4a0-8c1e" tenantid:"-" sap_passport:"-" x_b3_traceid:"5c1a8f0e2b3d4e7f" x_b3_spanid:"5c1a8f0e2b3d4e7f" x_b3_parentspanid:"-" b3:"5c1a8f0e2b3d4e7f-5c1a8f0e2b3d4e7f"`
+
+	msg, ok := ParseLine(input)
+	if !ok {
+		t.Fatal("Expected RTR log to be parsed")
+	}
+	if msg.HasParseError {
+		t.Fatal("Expected access log to be parsed without error")
+	}
+
+	checks := map[string][2]string{
+		"Type":          {msg.Type, "router"},
+		"Host":          {msg.Host, "orders.cfapps.eu10.hana.ondemand.com"},
+		"Method":        {msg.Method, "POST"},
+		"Request":       {msg.Request, "/api/orders?id=42"},
+		"UserAgent":     {msg.UserAgent, "Mozilla/5.0 (X11; Linux x86_64)"},
+		"RemoteIP":      {msg.RemoteIP, "10.0.72.9"},
+		"AppIndex":      {msg.AppIndex, "2"},
+		"TraceID":       {msg.TraceID, "5c1a8f0e2b3d4e7f"},
+		"VcapRequestID": {msg.VcapRequestID, "5c1a8f0e-2b3d-4e7f-9a10-bb2c3d4e5f60"},
+		"CorrelationID": {msg.CorrelationID, "d2b5f4a0-8c1e"},
+	}
+	for field, values := range checks {
+		if values[0] != values[1] {
+			t.Errorf("Expected %s to be %q, got %q", field, values[1], values[0])
+		}
+	}
+
+	if msg.ResponseStatus != 503 || msg.RequestSizeB != 2048 || msg.ResponseSizeB != 67 {
+		t.Errorf("Unexpected status/sizes: %d %d %d", msg.ResponseStatus, msg.RequestSizeB, msg.ResponseSizeB)
+	}
+	if msg.ResponseTimeMs < 31.45 || msg.ResponseTimeMs > 31.46 {
+		t.Errorf("Expected response time of 31.457ms, got %f", msg.ResponseTimeMs)
+	}
+	if msg.GorouterTimeMs < 0.21 || msg.GorouterTimeMs > 0.22 {
+		t.Errorf("Expected gorouter time of 0.218ms, got %f", msg.GorouterTimeMs)
+	}
+	if v, _ := msg.Field("x_forwarded_for"); v != "203.0.113.7, 10.0.72.9" {
+		t.Errorf("Expected x_forwarded_for field, got %q", v)
+	}
+	if v, _ := msg.Field("response_status"); v != "503" {
+		t.Errorf("Expected response_status field 503, got %q", v)
+	}
+	if !strings.HasPrefix(msg.Message, "orders.cfapps.eu10.hana.ondemand.com - [") {
+		t.Errorf("Expected original access log line as message, got %s", msg.Message)
+	}
+}
+
+func TestParseLine_RouterAccessLogMissingValues(t *testing.T) {
+	input := `2024-01-20T09:37:58.99+0100 [RTR/0] OUT test.example.com - [2024-01-20T08:37:58.990006873Z] "GET / HTTP/1.1" 404 0 0 "-" "-" "10.0.0.1:1234" "-" vcap_request_id:"abc" response_time:0.001 app_index:"-" x_correlationid:"-"`
+
+	msg, ok := ParseLine(input)
+	if !ok {
+		t.Fatal("Expected RTR log to be parsed")
+	}
+	if msg.Type != "router" {
+		t.Fatalf("Expected router type, got %q", msg.Type)
+	}
+	if msg.AppIndex != "" || msg.CorrelationID != "" {
+		t.Errorf("Expected '-' values to be empty, got app index %q and correlation ID %q", msg.AppIndex, msg.CorrelationID)
+	}
+}
+
+func TestParseLine_Time(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Time
+	}{
+		{
+			"CF timestamp with offset",
+			`2023-04-30T08:39:15.71+0200 [APP/PROC/WEB/0] OUT plain text`,
+			time.Date(2023, 4, 30, 6, 39, 15, 710_000_000, time.UTC),
+		},
+		{
+			"CF timestamp with negative offset",
+			`2023-04-30T01:39:15.71-0500 [APP/PROC/WEB/0] OUT plain text`,
+			time.Date(2023, 4, 30, 6, 39, 15, 710_000_000, time.UTC),
+		},
+		{
+			"written_at preferred over CF timestamp",
+			`2023-04-30T08:39:15.71+0200 [APP/PROC/WEB/0] OUT {"written_at":"2023-04-30T06:39:15.716123Z","level":"INFO","msg":"x"}`,
+			time.Date(2023, 4, 30, 6, 39, 15, 716_123_000, time.UTC),
+		},
+		{
+			"invalid written_at falls back to CF timestamp",
+			`2023-04-30T08:39:15.71+0200 [APP/PROC/WEB/0] OUT {"written_at":"yesterday","level":"INFO","msg":"x"}`,
+			time.Date(2023, 4, 30, 6, 39, 15, 710_000_000, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, ok := ParseLine(tt.input)
+			if !ok {
+				t.Fatal("Expected log line to be parsed")
+			}
+			if !msg.Time.Equal(tt.expected) {
+				t.Errorf("Expected time %s, got %s", tt.expected, msg.Time)
+			}
+			if msg.Timestamp != "2023-04-30T08:39:15.71" && msg.Timestamp != "2023-04-30T01:39:15.71" {
+				t.Errorf("Expected CF timestamp to be kept, got %s", msg.Timestamp)
+			}
+		})
+	}
+
+	msg, _ := ParseLine("test")
+	if !msg.Time.IsZero() {
+		t.Errorf("Expected no time for unstructured line, got %s", msg.Time)
+	}
+}
+
+func TestLogMessage_Source(t *testing.T) {
+	tests := []struct {
+		source       string
+		expectedType string
+		expectedInst string
+		expectedAbbr string
+	}{
+		{"APP/PROC/WEB/2", "APP", "2", "WEB/2"},
+		{"APP/PROC/WORKER/0", "APP", "0", "WORKER/0"},
+		{"APP/TASK/migrate/0", "APP", "0", "TASK/migrate"},
+		{"RTR/6", "RTR", "6", "RTR"},
+		{"CELL/0", "CELL", "0", "CELL"},
+		{"STG/0", "STG", "0", "STG"},
+		{"API", "API", "", "API"},
+		{"", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		msg := &LogMessage{Source: tt.source}
+		if got := msg.SourceType(); got != tt.expectedType {
+			t.Errorf("Source %q: expected type %q, got %q", tt.source, tt.expectedType, got)
+		}
+		if got := msg.Instance(); got != tt.expectedInst {
+			t.Errorf("Source %q: expected instance %q, got %q", tt.source, tt.expectedInst, got)
+		}
+		if got := msg.ShortSource(); got != tt.expectedAbbr {
+			t.Errorf("Source %q: expected short source %q, got %q", tt.source, tt.expectedAbbr, got)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/dedupe"
+	"github.com/saschakiefer/cf-log-pretty/internal/formatter"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// levels are always listed, other levels only if they occur
+var levels = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "-----"}
+
+// percentiles of the router response times
+var percentiles = []float64{50, 90, 95, 99}
+
+// maxMinutes limits the error rate table to the most recent minutes
+const maxMinutes = 15
+
+// maxResponseTimes limits the router response times kept for the percentiles. Beyond it, a uniform random sample of
+// all response times is kept.
+const maxResponseTimes = 10000
+
+// Stats collects statistics of log messages. It is safe for concurrent use.
+type Stats struct {
+	Top int // number of loggers and errors listed
+
+	mu            sync.Mutex
+	total         int
+	levels        map[string]int
+	loggers       map[string]int
+	errors        map[string]int    // per normalised error message
+	errorExamples map[string]string // first original message per normalised error message
+	errorMinutes  map[time.Time]int // of the last maxMinutes minutes
+	firstMinute   time.Time
+	lastMinute    time.Time
+	statusCodes   map[int]int
+	requests      int
+	responseTimes []float64 // sample of at most maxResponseTimes
+	maxResponse   float64
+}
+
+func New(top int) *Stats {
+	return &Stats{
+		Top:           top,
+		levels:        map[string]int{},
+		loggers:       map[string]int{},
+		errors:        map[string]int{},
+		errorExamples: map[string]string{},
+		errorMinutes:  map[time.Time]int{},
+		statusCodes:   map[int]int{},
+	}
+}
+
+// Add counts msg
+func (s *Stats) Add(msg *parser.LogMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.total++
+
+	level := strings.ToUpper(strings.TrimSpace(msg.Level))
+	if level == "" {
+		level = "-----"
+	}
+	s.levels[level]++
+
+	if msg.Logger != "" {
+		s.loggers[msg.Logger]++
+	}
+
+	minute := msg.Time.Truncate(time.Minute)
+	if !msg.Time.IsZero() {
+		if s.firstMinute.IsZero() || minute.Before(s.firstMinute) {
+			s.firstMinute = minute
+		}
+		if minute.After(s.lastMinute) {
+			s.lastMinute = minute
+			for m := range s.errorMinutes {
+				if m.Before(s.windowStart()) {
+					delete(s.errorMinutes, m)
+				}
+			}
+		}
+	}
+
+	if level == "ERROR" {
+		key := dedupe.Normalize(firstLine(msg.Message))
+		if _, ok := s.errorExamples[key]; !ok {
+			s.errorExamples[key] = firstLine(msg.Message)
+		}
+		s.errors[key]++
+		if !msg.Time.IsZero() && !minute.Before(s.windowStart()) {
+			s.errorMinutes[minute]++
+		}
+	}
+
+	if msg.Type == "router" {
+		s.statusCodes[msg.ResponseStatus]++
+		s.requests++
+		s.maxResponse = max(s.maxResponse, msg.ResponseTimeMs)
+
+		// Reservoir sampling: the n-th response time replaces a random one with probability maxResponseTimes/n
+		if len(s.responseTimes) < maxResponseTimes {
+			s.responseTimes = append(s.responseTimes, msg.ResponseTimeMs)
+		} else if i := rand.IntN(s.requests); i < maxResponseTimes {
+			s.responseTimes[i] = msg.ResponseTimeMs
+		}
+	}
+}
+
+// windowStart returns the first minute of the error rate table
+func (s *Stats) windowStart() time.Time {
+	return s.lastMinute.Add(-(maxMinutes - 1) * time.Minute)
+}
+
+// Render returns the statistics as tables
+func (s *Stats) Render() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sb strings.Builder
+	heading := func(title string) {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(title + "\n")
+	}
+	row := func(format string, a ...any) {
+		sb.WriteString(strings.TrimRight(fmt.Sprintf(format, a...), " ") + "\n")
+	}
+
+	heading(fmt.Sprintf("Levels (%d messages)", s.total))
+	for _, level := range append(slices.Clone(levels), otherKeys(s.levels, levels)...) {
+		row("  %s %8d %s", formatter.LevelColorizer(level)("%-5s", level), s.levels[level], percent(s.levels[level], s.total))
+	}
+
+	if len(s.loggers) > 0 {
+		heading("Top loggers")
+		for _, logger := range top(s.loggers, s.Top) {
+			row("  %8d  %s", s.loggers[logger], logger)
+		}
+	}
+
+	if len(s.errors) > 0 {
+		heading("Top errors")
+		for _, key := range top(s.errors, s.Top) {
+			row("  %8d  %s", s.errors[key], s.errorExamples[key])
+		}
+	}
+
+	if !s.firstMinute.IsZero() {
+		minutes := int(s.lastMinute.Sub(s.firstMinute)/time.Minute) + 1
+		heading(fmt.Sprintf("Errors per minute (average %.1f)", float64(s.levels["ERROR"])/float64(minutes)))
+
+		first := s.firstMinute
+		if minutes > maxMinutes {
+			first = s.windowStart()
+			row("  (last %d of %d minutes)", maxMinutes, minutes)
+		}
+		for minute := first; !minute.After(s.lastMinute); minute = minute.Add(time.Minute) {
+			count := s.errorMinutes[minute]
+			row("  %s %8d %s", minute.Local().Format("15:04"), count, strings.Repeat("▇", min(count, 50)))
+		}
+	}
+
+	if len(s.statusCodes) > 0 {
+		requests := s.requests
+		heading(fmt.Sprintf("Router status codes (%d requests)", requests))
+		for _, status := range slices.Sorted(maps.Keys(s.statusCodes)) {
+			row("  %s %8d %s", formatter.StatusColorizer(status)("%-5d", status), s.statusCodes[status], percent(s.statusCodes[status], requests))
+		}
+
+		heading("Router response times")
+		sorted := slices.Sorted(slices.Values(s.responseTimes))
+		var parts []string
+		for _, p := range percentiles {
+			parts = append(parts, fmt.Sprintf("p%.0f %.0fms", p, Percentile(sorted, p)))
+		}
+		parts = append(parts, fmt.Sprintf("max %.0fms", s.maxResponse))
+		row("  %s", strings.Join(parts, "  "))
+	}
+
+	return sb.String()
+}
+
+// Percentile returns the p-th percentile of the sorted values, using the nearest-rank method
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// top returns the n keys with the highest counts, ties sorted by key
+func top(counts map[string]int, n int) []string {
+	keys := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		return cmp.Or(counts[b]-counts[a], strings.Compare(a, b))
+	})
+	return keys[:min(n, len(keys))]
+}
+
+// otherKeys returns the sorted keys of counts that are not in known
+func otherKeys(counts map[string]int, known []string) []string {
+	var result []string
+	for _, key := range slices.Sorted(maps.Keys(counts)) {
+		if !slices.Contains(known, key) {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+func percent(count int, total int) string {
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%5.1f%%", float64(count)*100/float64(total))
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
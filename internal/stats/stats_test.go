@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+func TestStats_Render(t *testing.T) {
+	color.NoColor = true
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	s := New(2)
+	add := func(minute int, level string, logger string, message string) {
+		s.Add(&parser.LogMessage{Time: base.Add(time.Duration(minute) * time.Minute), Level: level, Logger: logger, Message: message})
+	}
+
+	add(0, "INFO", "com.foo.A", "started")
+	add(0, "ERROR", "com.foo.B", "connection to 10.0.0.1 refused")
+	add(0, "ERROR", "com.foo.B", "connection to 10.0.0.2 refused")
+	add(2, "ERROR", "com.foo.C", "timeout\nat com.foo.C")
+	add(2, "INFO", "com.foo.A", "done")
+	for _, ms := range []float64{10, 20, 30, 40, 500} {
+		s.Add(&parser.LogMessage{Time: base, Type: "router", Level: "-----", ResponseStatus: 200, ResponseTimeMs: ms})
+	}
+	s.Add(&parser.LogMessage{Time: base, Type: "router", Level: "-----", ResponseStatus: 502, ResponseTimeMs: 1000})
+
+	expected := `Levels (11 messages)
+  TRACE        0   0.0%
+  DEBUG        0   0.0%
+  INFO         2  18.2%
+  WARN         0   0.0%
+  ERROR        3  27.3%
+  -----        6  54.5%
+
+Top loggers
+         2  com.foo.A
+         2  com.foo.B
+
+Top errors
+         2  connection to 10.0.0.1 refused
+         1  timeout
+
+Errors per minute (average 1.0)
+  12:00        2 ▇▇
+  12:01        0
+  12:02        1 ▇
+
+Router status codes (6 requests)
+  200          5  83.3%
+  502          1  16.7%
+
+Router response times
+  p50 30ms  p90 1000ms  p95 1000ms  p99 1000ms  max 1000ms
+`
+	if got := s.Render(); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestStats_RenderEmpty(t *testing.T) {
+	color.NoColor = true
+
+	output := New(10).Render()
+	if !strings.HasPrefix(output, "Levels (0 messages)\n") || strings.Contains(output, "Top") || strings.Contains(output, "Router") {
+		t.Errorf("Expected only level counts, got:\n%s", output)
+	}
+}
+
+func TestStats_RenderLimitsMinutes(t *testing.T) {
+	color.NoColor = true
+
+	s := New(10)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	s.Add(&parser.LogMessage{Time: base, Level: "ERROR", Message: "x"})
+	s.Add(&parser.LogMessage{Time: base.Add(time.Hour), Level: "ERROR", Message: "x"})
+
+	output := s.Render()
+	if !strings.Contains(output, "(last 15 of 61 minutes)\n  12:46        0\n") || strings.Contains(output, "12:45") {
+		t.Errorf("Expected the last 15 minutes, got:\n%s", output)
+	}
+}
+
+func TestStats_Bounded(t *testing.T) {
+	color.NoColor = true
+
+	s := New(10)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	for minute := range 120 {
+		s.Add(&parser.LogMessage{Time: base.Add(time.Duration(minute) * time.Minute), Level: "ERROR", Message: "x"})
+	}
+	for i := range 3 * maxResponseTimes {
+		s.Add(&parser.LogMessage{Time: base, Type: "router", Level: "-----", ResponseStatus: 200, ResponseTimeMs: float64(i % 100)})
+	}
+
+	if len(s.errorMinutes) != maxMinutes {
+		t.Errorf("Expected errors of the last %d minutes to be kept, got %d minutes", maxMinutes, len(s.errorMinutes))
+	}
+	if len(s.responseTimes) != maxResponseTimes {
+		t.Errorf("Expected %d response times to be kept, got %d", maxResponseTimes, len(s.responseTimes))
+	}
+
+	output := s.Render()
+	if !strings.Contains(output, "Router status codes (30000 requests)") || !strings.Contains(output, "max 99ms") {
+		t.Errorf("Expected all requests to be counted, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Errors per minute (average 1.0)") || !strings.Contains(output, "13:59        1") {
+		t.Errorf("Expected error rate of all minutes, got:\n%s", output)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		p        float64
+		expected float64
+	}{
+		{0, 1},
+		{50, 5},
+		{90, 9},
+		{95, 10},
+		{100, 10},
+	}
+	for _, tt := range tests {
+		if got := Percentile(sorted, tt.p); got != tt.expected {
+			t.Errorf("Percentile %.0f: expected %.0f, got %.0f", tt.p, tt.expected, got)
+		}
+	}
+
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Expected 0 for no values, got %f", got)
+	}
+}
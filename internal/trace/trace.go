@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package trace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/formatter"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// Timeline collects all messages belonging to one correlation ID
+type Timeline struct {
+	ID       string
+	Messages []*parser.LogMessage
+}
+
+func New(id string) *Timeline {
+	return &Timeline{
+		ID: id,
+	}
+}
+
+// Add records msg if it belongs to the traced correlation ID and reports whether it did
+func (t *Timeline) Add(msg *parser.LogMessage) bool {
+	if !Matches(msg, t.ID) {
+		return false
+	}
+	t.Messages = append(t.Messages, msg)
+	return true
+}
+
+// Matches checks if msg carries the given correlation ID, either as JSON field "correlation_id"
+// or as "x_correlationid" / "vcap_request_id" of a router access log
+func Matches(msg *parser.LogMessage, id string) bool {
+	return msg.CorrelationID == id || msg.VcapRequestID == id
+}
+
+// Render orders the collected messages by timestamp and renders them as an indented timeline,
+// showing the time elapsed since the previous step in front of each message
+func (t *Timeline) Render(cfg *config.Config) string {
+	if len(t.Messages) == 0 {
+		return fmt.Sprintf("Trace %s: no messages found", t.ID)
+	}
+
+	messages := append([]*parser.LogMessage(nil), t.Messages...)
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Time.Before(messages[j].Time)
+	})
+
+	sources := map[string]bool{}
+	for _, msg := range messages {
+		sources[msg.Source] = true
+	}
+
+	first := messages[0].Time
+	last := messages[len(messages)-1].Time
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Trace %s: %d messages from %d sources in %s\n", t.ID, len(messages), len(sources), formatDuration(last.Sub(first)))
+
+	previous := first
+	for i, msg := range messages {
+		lines := strings.Split(formatter.FormatAfter(msg, formatter.LevelColorizer(msg.Level), cfg, previous), "\n")
+
+		elapsed := ""
+		if !msg.Time.IsZero() {
+			elapsed = "+" + formatDuration(msg.Time.Sub(previous))
+			previous = msg.Time
+		}
+
+		connector := "├─"
+		indent := "│ "
+		if i == len(messages)-1 {
+			connector = "└─"
+			indent = "  "
+		}
+		fmt.Fprintf(&sb, "%s %9s  %-16s %s\n", connector, elapsed, msg.Source, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(&sb, "%s %9s  %-16s %s\n", indent, "", "", line)
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// formatDuration renders a duration with millisecond precision, e.g. "1.234s"
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
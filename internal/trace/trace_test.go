@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package trace
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// helper to parse a CF timestamp
+func ts(value string) time.Time {
+	t, _ := time.Parse("2006-01-02T15:04:05.999", value)
+	return t
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      *parser.LogMessage
+		expected bool
+	}{
+		{"JSON correlation ID", &parser.LogMessage{CorrelationID: "abc"}, true},
+		{"Other JSON correlation ID", &parser.LogMessage{CorrelationID: "xyz"}, false},
+		{"Router vcap_request_id", &parser.LogMessage{Type: "router", VcapRequestID: "abc"}, true},
+		{"Router x_correlationid", &parser.LogMessage{Type: "router", VcapRequestID: "xyz", CorrelationID: "abc"}, true},
+		{"Router other request", &parser.LogMessage{Type: "router", VcapRequestID: "abcd"}, false},
+		{"Plain message mentioning the ID", &parser.LogMessage{Message: "processing abc"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Matches(tt.msg, "abc")
+			if got != tt.expected {
+				t.Errorf("Expected match = %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTimeline_Render(t *testing.T) {
+	timeline := New("abc")
+
+	messages := []*parser.LogMessage{
+		{Timestamp: "2024-01-20T09:37:59.25", Time: ts("2024-01-20T09:37:59.25"), Source: "RTR/0", Level: "-----", Message: `"GET /orders HTTP/1.1" 200 x_correlationid:"abc"`, CorrelationID: "abc"},
+		{Timestamp: "2024-01-20T09:37:58.99", Time: ts("2024-01-20T09:37:58.99"), Source: "APP/PROC/WEB/1", Level: "INFO", Logger: "com.foo.Orders", Message: "start", CorrelationID: "abc"},
+		{Timestamp: "2024-01-20T09:37:59.00", Time: ts("2024-01-20T09:37:59.00"), Source: "APP/PROC/WEB/0", Level: "INFO", Logger: "com.foo.Orders", Message: "unrelated", CorrelationID: "xyz"},
+		{Timestamp: "2024-01-20T09:37:59.24", Time: ts("2024-01-20T09:37:59.24"), Source: "APP/PROC/WEB/1", Level: "ERROR", Logger: "com.foo.Orders", Message: "failed", CorrelationID: "abc", StackTrace: []string{"java.lang.Exception"}},
+	}
+	for _, msg := range messages {
+		timeline.Add(msg)
+	}
+
+	output := timeline.Render(&config.Config{})
+	lines := strings.Split(output, "\n")
+
+	if lines[0] != "Trace abc: 3 messages from 2 sources in 0.260s" {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 lines (header, 3 messages, 1 stacktrace line), got %d:\n%s", len(lines), output)
+	}
+
+	expected := []struct {
+		prefix   string
+		contains string
+	}{
+		{"├─   +0.000s  APP/PROC/WEB/1", "start"},
+		{"├─   +0.250s  APP/PROC/WEB/1", "failed"},
+		{"│ ", "java.lang.Exception"},
+		{"└─   +0.010s  RTR/0", "GET /orders"},
+	}
+	for i, e := range expected {
+		line := lines[i+1]
+		if !strings.HasPrefix(line, e.prefix) || !strings.Contains(line, e.contains) {
+			t.Errorf("Expected line %d to start with %q and contain %q, got: %s", i+1, e.prefix, e.contains, line)
+		}
+	}
+}
+
+func TestTimeline_RenderEmpty(t *testing.T) {
+	output := New("abc").Render(&config.Config{})
+
+	if output != "Trace abc: no messages found" {
+		t.Errorf("Unexpected output: %s", output)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Key is a single key press. Special keys use the names below, printable keys their character.
+type Key string
+
+const (
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyPageUp    Key = "pgup"
+	KeyPageDown  Key = "pgdown"
+	KeyHome      Key = "home"
+	KeyEnd       Key = "end"
+	KeyEnter     Key = "enter"
+	KeyEscape    Key = "esc"
+	KeyBackspace Key = "backspace"
+	KeyCtrlC     Key = "ctrl+c"
+)
+
+// escapeSequences maps the VT100/xterm sequences of special keys
+var escapeSequences = map[string]Key{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1bOH":  KeyHome,
+	"\x1bOF":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+}
+
+// ParseKeys splits raw terminal input into key presses. Unknown escape sequences are dropped.
+func ParseKeys(input []byte) []Key {
+	var keys []Key
+	s := string(input)
+
+	for len(s) > 0 {
+		if s[0] == '\x1b' {
+			if len(s) == 1 {
+				keys = append(keys, KeyEscape)
+				break
+			}
+
+			matched := false
+			for seq, key := range escapeSequences {
+				if strings.HasPrefix(s, seq) {
+					keys = append(keys, key)
+					s = s[len(seq):]
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+
+			if s[1] != '[' && s[1] != 'O' {
+				// Escape followed by a regular key
+				keys = append(keys, KeyEscape)
+				s = s[1:]
+				continue
+			}
+
+			// Skip unknown CSI sequence up to its final byte
+			end := 2
+			for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+				end++
+			}
+			s = s[min(end+1, len(s)):]
+			continue
+		}
+
+		switch s[0] {
+		case '\r', '\n':
+			keys = append(keys, KeyEnter)
+		case 0x7f, 0x08:
+			keys = append(keys, KeyBackspace)
+		case 0x03:
+			keys = append(keys, KeyCtrlC)
+		default:
+			r, size := utf8.DecodeRuneInString(s)
+			if r >= 0x20 {
+				keys = append(keys, Key(s[:size]))
+			}
+			s = s[size:]
+			continue
+		}
+		s = s[1:]
+	}
+
+	return keys
+}
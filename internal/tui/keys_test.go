@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Key
+	}{
+		{"Printable keys", "q/x", []Key{"q", "/", "x"}},
+		{"Arrow keys", "\x1b[A\x1b[B", []Key{KeyUp, KeyDown}},
+		{"Application mode arrow keys", "\x1bOA\x1bOB", []Key{KeyUp, KeyDown}},
+		{"Page keys", "\x1b[5~\x1b[6~", []Key{KeyPageUp, KeyPageDown}},
+		{"Home and end", "\x1b[H\x1b[F\x1b[1~\x1b[4~", []Key{KeyHome, KeyEnd, KeyHome, KeyEnd}},
+		{"Enter, backspace and Ctrl+C", "\r\x7f\x03", []Key{KeyEnter, KeyBackspace, KeyCtrlC}},
+		{"Escape alone", "\x1b", []Key{KeyEscape}},
+		{"Escape followed by key", "\x1bq", []Key{KeyEscape, "q"}},
+		{"Unknown sequence is dropped", "\x1b[1;5Cx", []Key{"x"}},
+		{"Unicode character", "ä", []Key{"ä"}},
+		{"Other control characters are dropped", "\x01a", []Key{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseKeys([]byte(tt.input))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
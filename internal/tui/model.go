@@ -0,0 +1,487 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/formatter"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// scrollbackSize is the number of messages kept for scrolling, older ones are dropped
+const scrollbackSize = 10000
+
+// levels can be toggled with the keys 1 to 6
+var levels = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "-----"}
+
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// ansiPrefixRegex matches a color code at the start of a string
+var ansiPrefixRegex = regexp.MustCompile(`^` + ansiRegex.String())
+
+const (
+	reverseOn  = "\x1b[7m"
+	reverseOff = "\x1b[27m"
+	resetStyle = "\x1b[0m"
+	clearLine  = "\x1b[K"
+)
+
+type entry struct {
+	msg      *parser.LogMessage
+	lines    []string // formatted message, the first line is the headline
+	plain    string   // lower case text without colors, for searching
+	expanded bool
+}
+
+type row struct {
+	text  string
+	entry int // index into Model.entries
+}
+
+// Model holds the state of the interactive mode and renders it, independent of the terminal
+type Model struct {
+	cfg          *config.Config
+	entries      []*entry
+	pending      []*entry // received while paused
+	hiddenLevels map[string]bool
+	excluded     map[string]bool // loggers excluded at runtime
+	expandAll    bool
+	paused       bool
+	ended        bool
+	follow       bool // keep the newest message selected
+	selected     int  // index into entries
+	top          int  // first row shown
+	search       string
+	searching    bool // search input is active
+	input        string
+	width        int
+	height       int
+	previousTime time.Time // of the previously added message, for relative timestamps
+}
+
+func NewModel(cfg *config.Config) *Model {
+	return &Model{
+		cfg:          cfg,
+		hiddenLevels: map[string]bool{},
+		excluded:     map[string]bool{},
+		follow:       true,
+		width:        80,
+		height:       24,
+	}
+}
+
+// Add formats msg and appends it to the scrollback, or keeps it back while paused
+func (m *Model) Add(msg *parser.LogMessage) {
+	formatted := formatter.FormatAfter(msg, formatter.LevelColorizer(msg.Level), m.cfg, m.previousTime)
+	if !msg.Time.IsZero() {
+		m.previousTime = msg.Time
+	}
+	formatted = strings.ReplaceAll(formatted, "\t", "    ")
+
+	e := &entry{
+		msg:   msg,
+		lines: strings.Split(formatted, "\n"),
+		plain: strings.ToLower(ansiRegex.ReplaceAllString(formatted, "")),
+	}
+
+	if m.paused {
+		// Only the newest messages would be kept after resuming anyway
+		m.pending = append(m.pending, e)
+		if overflow := len(m.pending) - scrollbackSize; overflow > 0 {
+			m.pending = m.pending[overflow:]
+		}
+		return
+	}
+	m.append(e)
+}
+
+func (m *Model) append(entries ...*entry) {
+	m.entries = append(m.entries, entries...)
+
+	if overflow := len(m.entries) - scrollbackSize; overflow > 0 {
+		m.entries = m.entries[overflow:]
+		m.selected = max(0, m.selected-overflow)
+	}
+
+	if m.follow {
+		m.selectLast()
+	}
+}
+
+// End marks the input as completely read
+func (m *Model) End() {
+	m.ended = true
+}
+
+func (m *Model) Resize(width int, height int) {
+	m.width = max(width, 20)
+	m.height = max(height, 2)
+}
+
+// HandleKey applies a key press and reports whether the user wants to quit
+func (m *Model) HandleKey(key Key) bool {
+	if m.searching {
+		m.handleSearchKey(key)
+		return false
+	}
+
+	switch key {
+	case "q", KeyCtrlC:
+		return true
+	case KeyUp, "k":
+		m.move(-1)
+	case KeyDown, "j":
+		m.move(1)
+	case KeyPageUp:
+		m.move(-m.bodyHeight())
+	case KeyPageDown:
+		m.move(m.bodyHeight())
+	case KeyHome, "g":
+		m.follow = false
+		if visible := m.visible(); len(visible) > 0 {
+			m.selected = visible[0]
+		}
+	case KeyEnd, "G":
+		m.selectLast()
+		m.follow = true
+	case KeyEnter, "e":
+		if m.isVisible(m.selected) {
+			m.entries[m.selected].expanded = !m.entries[m.selected].expanded
+		}
+	case "E":
+		m.expandAll = !m.expandAll
+		for _, e := range m.entries {
+			e.expanded = false
+		}
+	case " ", "p":
+		m.paused = !m.paused
+		if !m.paused {
+			pending := m.pending
+			m.pending = nil
+			m.append(pending...)
+		}
+	case "1", "2", "3", "4", "5", "6":
+		level := levels[key[0]-'1']
+		m.hiddenLevels[level] = !m.hiddenLevels[level]
+		m.keepSelectionVisible()
+	case "x":
+		if m.isVisible(m.selected) && m.entries[m.selected].msg.Logger != "" {
+			m.excluded[m.entries[m.selected].msg.Logger] = true
+			m.keepSelectionVisible()
+		}
+	case "X":
+		m.excluded = map[string]bool{}
+	case "/":
+		m.searching = true
+		m.input = ""
+	case "n":
+		m.findNext(1)
+	case "N":
+		m.findNext(-1)
+	case KeyEscape:
+		m.search = ""
+	}
+	return false
+}
+
+func (m *Model) handleSearchKey(key Key) {
+	switch key {
+	case KeyEnter:
+		m.searching = false
+		m.search = strings.ToLower(m.input)
+		if m.search != "" && !m.matches(m.selected) {
+			m.findNext(1)
+		}
+	case KeyEscape, KeyCtrlC:
+		m.searching = false
+	case KeyBackspace:
+		if m.input != "" {
+			_, size := utf8.DecodeLastRuneInString(m.input)
+			m.input = m.input[:len(m.input)-size]
+		}
+	default:
+		if utf8.RuneCountInString(string(key)) == 1 {
+			m.input += string(key)
+		}
+	}
+}
+
+// findNext selects the next (direction 1) or previous (direction -1) visible message matching the search
+func (m *Model) findNext(direction int) {
+	if m.search == "" {
+		return
+	}
+
+	visible := m.visible()
+	pos := m.position(visible)
+	for i := 1; i <= len(visible); i++ {
+		candidate := visible[((pos+direction*i)%len(visible)+len(visible))%len(visible)]
+		if m.matches(candidate) {
+			m.selected = candidate
+			m.follow = false
+			return
+		}
+	}
+}
+
+func (m *Model) matches(i int) bool {
+	return m.search != "" && i < len(m.entries) && strings.Contains(m.entries[i].plain, m.search)
+}
+
+// move changes the selection by delta visible messages
+func (m *Model) move(delta int) {
+	visible := m.visible()
+	if len(visible) == 0 {
+		return
+	}
+
+	pos := min(max(m.position(visible)+delta, 0), len(visible)-1)
+	m.selected = visible[pos]
+	m.follow = pos == len(visible)-1
+}
+
+func (m *Model) selectLast() {
+	if visible := m.visible(); len(visible) > 0 {
+		m.selected = visible[len(visible)-1]
+	}
+}
+
+// keepSelectionVisible moves the selection to the next visible message after filters changed
+func (m *Model) keepSelectionVisible() {
+	if m.follow || m.isVisible(m.selected) {
+		if m.follow {
+			m.selectLast()
+		}
+		return
+	}
+
+	visible := m.visible()
+	if len(visible) == 0 {
+		return
+	}
+	m.selected = visible[min(m.position(visible), len(visible)-1)]
+}
+
+// position returns the index of the selected message in visible, or of the first visible message after it
+func (m *Model) position(visible []int) int {
+	for pos, i := range visible {
+		if i >= m.selected {
+			return pos
+		}
+	}
+	return max(len(visible)-1, 0)
+}
+
+func (m *Model) isVisible(i int) bool {
+	if i < 0 || i >= len(m.entries) {
+		return false
+	}
+
+	msg := m.entries[i].msg
+	level := strings.ToUpper(msg.Level)
+	if !isKnownLevel(level) {
+		level = "-----"
+	}
+	return !m.hiddenLevels[level] && !m.excluded[msg.Logger]
+}
+
+func isKnownLevel(level string) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// visible returns the indexes of all messages passing the runtime filters
+func (m *Model) visible() []int {
+	result := make([]int, 0, len(m.entries))
+	for i := range m.entries {
+		if m.isVisible(i) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func (m *Model) rows() []row {
+	var result []row
+	for _, i := range m.visible() {
+		e := m.entries[i]
+
+		if len(e.lines) > 1 && !e.expanded && !m.expandAll {
+			result = append(result, row{text: fmt.Sprintf("%s [+%d]", e.lines[0], len(e.lines)-1), entry: i})
+			continue
+		}
+		for _, line := range e.lines {
+			result = append(result, row{text: line, entry: i})
+		}
+	}
+	return result
+}
+
+func (m *Model) bodyHeight() int {
+	return m.height - 1
+}
+
+// View renders the complete screen: the visible part of the scrollback and a status bar
+func (m *Model) View() string {
+	rows := m.rows()
+	height := m.bodyHeight()
+
+	// Scroll so the selected message is visible
+	first, last := -1, -1
+	for i, r := range rows {
+		if r.entry == m.selected {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	switch {
+	case m.follow:
+		m.top = len(rows) - height
+	case first >= 0 && first < m.top:
+		m.top = first
+	case last >= m.top+height:
+		m.top = min(first, last-height+1)
+	}
+	m.top = max(0, min(m.top, len(rows)-height))
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H")
+
+	for i := m.top; i < m.top+height; i++ {
+		if i < len(rows) {
+			text := truncate(rows[i].text, m.width)
+			if m.search != "" {
+				text = highlight(text, m.search)
+			}
+			if rows[i].entry == m.selected && !m.follow {
+				text = "\x1b[1m>" + resetStyle + truncate(text, m.width-1)
+			}
+			sb.WriteString(text + resetStyle)
+		}
+		sb.WriteString(clearLine + "\r\n")
+	}
+
+	sb.WriteString(reverseOn + padRight(truncate(m.status(), m.width), m.width) + resetStyle)
+	return sb.String()
+}
+
+func (m *Model) status() string {
+	if m.searching {
+		return "/" + m.input + "█"
+	}
+
+	state := "LIVE"
+	switch {
+	case m.paused:
+		state = fmt.Sprintf("PAUSED (+%d)", len(m.pending))
+	case m.ended:
+		state = "END OF INPUT"
+	}
+
+	var levelState strings.Builder
+	for _, level := range levels {
+		if m.hiddenLevels[level] {
+			levelState.WriteString("·")
+		} else {
+			levelState.WriteByte(level[0])
+		}
+	}
+
+	parts := []string{
+		state,
+		fmt.Sprintf("%d/%d msgs", len(m.visible()), len(m.entries)),
+		"levels " + levelState.String(),
+	}
+	if len(m.excluded) > 0 {
+		parts = append(parts, fmt.Sprintf("%d loggers excluded", len(m.excluded)))
+	}
+	if m.search != "" {
+		parts = append(parts, "search: "+m.search)
+	}
+	parts = append(parts, "q:quit /:search n/N:next 1-6:levels x/X:exclude e/E:expand space:pause")
+
+	return " " + strings.Join(parts, " | ")
+}
+
+// truncate cuts s to width visible characters, keeping color codes intact
+func truncate(s string, width int) string {
+	var sb strings.Builder
+	visible := 0
+
+	for i := 0; i < len(s); {
+		if n := ansiCodeLen(s[i:]); n > 0 {
+			sb.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+
+		if visible == width {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		sb.WriteString(s[i : i+size])
+		visible++
+		i += size
+	}
+	return sb.String()
+}
+
+// highlight shows all case-insensitive occurrences of query in s in reverse video. Color codes in s are kept,
+// matches spanning color codes are not highlighted.
+func highlight(s string, query string) string {
+	var sb strings.Builder
+	lower := strings.ToLower(s)
+	if len(lower) != len(s) {
+		lower = s
+	}
+
+	for i := 0; i < len(s); {
+		if n := ansiCodeLen(s[i:]); n > 0 {
+			sb.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+
+		if strings.HasPrefix(lower[i:], query) && !strings.Contains(s[i:i+len(query)], "\x1b") {
+			sb.WriteString(reverseOn + s[i:i+len(query)] + reverseOff)
+			i += len(query)
+			continue
+		}
+
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
+
+// ansiCodeLen returns the length of the color code s starts with, or 0 if it doesn't start with one.
+// Only looking at the start keeps per-character callers linear in the line length.
+func ansiCodeLen(s string) int {
+	if s == "" || s[0] != '\x1b' {
+		return 0
+	}
+	return len(ansiPrefixRegex.FindString(s))
+}
+
+func padRight(s string, width int) string {
+	length := utf8.RuneCountInString(ansiRegex.ReplaceAllString(s, ""))
+	if length >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-length)
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+)
+
+// helper to build a model with one message per level
+func newTestModel() *Model {
+	m := NewModel(&config.Config{})
+	m.Resize(200, 10)
+
+	m.Add(&parser.LogMessage{Level: "DEBUG", Logger: "com.foo.Noise", Message: "polling"})
+	m.Add(&parser.LogMessage{Level: "INFO", Logger: "com.foo.Orders", Message: "order received"})
+	m.Add(&parser.LogMessage{Level: "ERROR", Logger: "com.foo.Orders", Message: "order failed", StackTrace: []string{"java.lang.Exception", "\tat com.foo.Orders.run(Orders.java:1)"}})
+	m.Add(&parser.LogMessage{Level: "INFO", Logger: "com.foo.Noise", Message: "polling again"})
+	return m
+}
+
+// helper returning the body lines of the rendered view without colors
+func body(m *Model) []string {
+	view := ansiRegex.ReplaceAllString(m.View(), "")
+	lines := strings.Split(view, "\r\n")
+	var result []string
+	for _, line := range lines[:len(lines)-1] {
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+func TestModel_CollapsesStackTraces(t *testing.T) {
+	m := newTestModel()
+
+	lines := body(m)
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %d: %v", len(lines), lines)
+	}
+	if !strings.HasSuffix(lines[2], "order failed [+2]") {
+		t.Errorf("Expected collapsed stack trace, got: %s", lines[2])
+	}
+
+	m.HandleKey("E")
+	if lines := body(m); len(lines) != 6 || !strings.Contains(lines[4], "    at com.foo.Orders.run") {
+		t.Errorf("Expected expanded stack trace, got: %v", lines)
+	}
+}
+
+func TestModel_ExpandSelected(t *testing.T) {
+	m := newTestModel()
+
+	m.HandleKey(KeyUp)
+	m.HandleKey(KeyEnter)
+
+	lines := body(m)
+	if len(lines) != 6 {
+		t.Fatalf("Expected 6 lines, got %d: %v", len(lines), lines)
+	}
+	if !strings.HasPrefix(lines[2], ">") {
+		t.Errorf("Expected selected message to be marked, got: %s", lines[2])
+	}
+}
+
+func TestModel_ToggleLevels(t *testing.T) {
+	m := newTestModel()
+
+	m.HandleKey("3") // INFO
+	lines := body(m)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines without INFO, got %d: %v", len(lines), lines)
+	}
+	if !strings.Contains(m.status(), "levels TD·WE-") {
+		t.Errorf("Expected status to show hidden INFO level, got: %s", m.status())
+	}
+
+	m.HandleKey("3")
+	if lines := body(m); len(lines) != 4 {
+		t.Errorf("Expected INFO to be shown again, got: %v", lines)
+	}
+}
+
+func TestModel_ExcludeLogger(t *testing.T) {
+	m := newTestModel()
+
+	// The last message (com.foo.Noise) is selected in follow mode
+	m.HandleKey("x")
+	lines := body(m)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines without com.foo.Noise, got %d: %v", len(lines), lines)
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "com.foo.Noise") {
+			t.Errorf("Expected com.foo.Noise to be excluded, got: %s", line)
+		}
+	}
+
+	m.HandleKey("X")
+	if lines := body(m); len(lines) != 4 {
+		t.Errorf("Expected exclusions to be cleared, got: %v", lines)
+	}
+}
+
+func TestModel_Search(t *testing.T) {
+	m := newTestModel()
+
+	for _, key := range []Key{"/", "O", "r", "d", "e", "x", KeyBackspace, "r", KeyEnter} {
+		m.HandleKey(key)
+	}
+
+	if m.search != "order" {
+		t.Fatalf("Expected search 'order', got %q", m.search)
+	}
+	if m.entries[m.selected].msg.Message != "order received" {
+		t.Errorf("Expected first match to be selected, got: %s", m.entries[m.selected].msg.Message)
+	}
+
+	m.HandleKey("n")
+	if m.entries[m.selected].msg.Message != "order failed" {
+		t.Errorf("Expected next match to be selected, got: %s", m.entries[m.selected].msg.Message)
+	}
+	m.HandleKey("n")
+	if m.entries[m.selected].msg.Message != "order received" {
+		t.Errorf("Expected search to wrap around, got: %s", m.entries[m.selected].msg.Message)
+	}
+	m.HandleKey("N")
+	if m.entries[m.selected].msg.Message != "order failed" {
+		t.Errorf("Expected previous match to be selected, got: %s", m.entries[m.selected].msg.Message)
+	}
+
+	if !strings.Contains(m.View(), reverseOn+"order"+reverseOff) {
+		t.Error("Expected matches to be highlighted")
+	}
+
+	m.HandleKey(KeyEscape)
+	if m.search != "" {
+		t.Errorf("Expected search to be cleared, got %q", m.search)
+	}
+}
+
+func TestModel_Pause(t *testing.T) {
+	m := newTestModel()
+
+	m.HandleKey(" ")
+	m.Add(&parser.LogMessage{Level: "INFO", Message: "while paused"})
+
+	if len(body(m)) != 4 {
+		t.Errorf("Expected no new message while paused, got: %v", body(m))
+	}
+	if !strings.HasPrefix(m.status(), " PAUSED (+1)") {
+		t.Errorf("Expected paused status, got: %s", m.status())
+	}
+
+	m.HandleKey(" ")
+	lines := body(m)
+	if len(lines) != 5 || !strings.HasSuffix(lines[4], "while paused") {
+		t.Errorf("Expected pending message after resume, got: %v", lines)
+	}
+}
+
+func TestModel_PauseKeepsScrollbackSize(t *testing.T) {
+	m := newTestModel()
+
+	m.HandleKey(" ")
+	for i := range scrollbackSize + 10 {
+		m.Add(&parser.LogMessage{Level: "INFO", Message: fmt.Sprintf("message %d", i)})
+	}
+
+	if len(m.pending) != scrollbackSize || m.pending[0].msg.Message != "message 10" {
+		t.Errorf("Expected the newest %d pending messages, got %d starting with %q", scrollbackSize, len(m.pending), m.pending[0].msg.Message)
+	}
+}
+
+func TestModel_ScrollsAndFollows(t *testing.T) {
+	m := NewModel(&config.Config{})
+	m.Resize(200, 4) // 3 body lines
+
+	for i := 0; i < 10; i++ {
+		m.Add(&parser.LogMessage{Level: "INFO", Message: string(rune('a' + i))})
+	}
+
+	lines := body(m)
+	if len(lines) != 3 || !strings.HasSuffix(lines[2], ": j") {
+		t.Fatalf("Expected the newest messages in follow mode, got: %v", lines)
+	}
+
+	m.HandleKey(KeyHome)
+	m.Add(&parser.LogMessage{Level: "INFO", Message: "k"})
+	if lines := body(m); !strings.HasSuffix(lines[0], ": a") {
+		t.Errorf("Expected view to stay at the top after new messages, got: %v", lines)
+	}
+
+	m.HandleKey(KeyPageDown)
+	if lines := body(m); !strings.HasSuffix(lines[2], ": d") {
+		t.Errorf("Expected page down to move by the body height, got: %v", lines)
+	}
+
+	m.HandleKey(KeyEnd)
+	if lines := body(m); !strings.HasSuffix(lines[2], ": k") {
+		t.Errorf("Expected end to follow the newest message, got: %v", lines)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	input := "\x1b[31mERROR\x1b[0m message"
+
+	got := truncate(input, 7)
+	if got != "\x1b[31mERROR\x1b[0m m" {
+		t.Errorf("Expected color codes to be kept, got %q", got)
+	}
+	if got := truncate("äöü", 2); got != "äö" {
+		t.Errorf("Expected truncation by characters, got %q", got)
+	}
+	if got := truncate("a\x1bb\x1b[1mc", 3); got != "a\x1bb\x1b[1m" {
+		t.Errorf("Expected escape characters without color code to count as characters, got %q", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := highlight("\x1b[31mTimeout\x1b[0m after timeout", "timeout")
+
+	expected := "\x1b[31m" + reverseOn + "Timeout" + reverseOff + "\x1b[0m after " + reverseOn + "timeout" + reverseOff
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2026. Sascha Kiefer.
+ * Licensed under the MIT license. See LICENSE file in the project root for details.
+ */
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/saschakiefer/cf-log-pretty/internal/config"
+	"github.com/saschakiefer/cf-log-pretty/internal/filter"
+	"github.com/saschakiefer/cf-log-pretty/internal/parser"
+	"golang.org/x/term"
+)
+
+// refreshInterval limits how often the screen is redrawn while messages arrive
+const refreshInterval = 50 * time.Millisecond
+
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	exitAltScreen  = "\x1b[?25h\x1b[?1049l"
+)
+
+// Run shows the messages passing f in a full-screen terminal UI until the user quits.
+// Keys are read from the controlling terminal, as stdin usually carries the logs.
+func Run(messages <-chan *parser.LogMessage, f *filter.Filter, cfg *config.Config) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("interactive mode requires a terminal: %w", err)
+	}
+	defer func() { _ = tty.Close() }()
+
+	fd := int(tty.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("interactive mode requires a terminal: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	_, _ = fmt.Fprint(tty, enterAltScreen)
+	defer func() { _, _ = fmt.Fprint(tty, exitAltScreen) }()
+
+	keys := make(chan []Key)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := tty.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- ParseKeys(buf[:n])
+		}
+	}()
+
+	model := NewModel(cfg)
+	width, height := 0, 0
+	render := func() {
+		_, _ = fmt.Fprint(tty, model.View())
+	}
+	// resized picks up terminal size changes and reports whether there was one
+	resized := func() bool {
+		w, h, err := term.GetSize(fd)
+		if err != nil || (w == width && h == height) {
+			return false
+		}
+		width, height = w, h
+		model.Resize(w, h)
+		return true
+	}
+	resized()
+	render()
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	dirty := false
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				model.End()
+			} else if f.Matches(msg) {
+				model.Add(msg)
+			}
+			dirty = true
+
+		case pressed, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, key := range pressed {
+				if model.HandleKey(key) {
+					return nil
+				}
+			}
+			render()
+			dirty = false
+
+		case <-ticker.C:
+			if resized() || dirty {
+				render()
+				dirty = false
+			}
+		}
+	}
+}